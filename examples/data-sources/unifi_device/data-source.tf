@@ -0,0 +1,7 @@
+data "unifi_device" "core" {
+  name = "Core Switch"
+}
+
+output "core_firmware" {
+  value = data.unifi_device.core.firmware_version
+}
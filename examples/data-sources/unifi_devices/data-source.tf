@@ -0,0 +1,7 @@
+data "unifi_devices" "pending" {
+  state = "pending_adoption"
+}
+
+output "pending_macs" {
+  value = data.unifi_devices.pending.devices[*].mac
+}
@@ -0,0 +1,7 @@
+data "unifi_network" "default" {
+  name = "Default"
+}
+
+data "unifi_network" "iot" {
+  vlan_id = 20
+}
@@ -0,0 +1,7 @@
+data "unifi_networks" "lan" {
+  purpose = "corporate"
+}
+
+output "lan_subnets" {
+  value = data.unifi_networks.lan.networks[*].subnet
+}
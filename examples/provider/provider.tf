@@ -3,10 +3,17 @@ provider "unifi" {
   password = var.password # optionally use UNIFI_PASSWORD env var
   api_url  = var.api_url  # optionally use UNIFI_API env var
 
+  # on UniFi OS consoles you can use an API key instead of a username and password
+  # api_key = var.api_key # optionally use UNIFI_API_KEY env var
+
   # you may need to allow insecure TLS communications unless you have configured
   # certificates for your controller
   allow_insecure = var.insecure # optionally use UNIFI_INSECURE env var
 
+  # alternatively trust the controller's self-signed certificate, or pin its fingerprint
+  # ca_cert_file           = "unifi-ca.pem"  # optionally use UNIFI_CA_CERT_FILE env var
+  # tls_fingerprint_sha256 = var.fingerprint # optionally use UNIFI_TLS_FINGERPRINT_SHA256 env var
+
   # if you are not configuring the default site, you can change the site
   # site = "foo" or optionally use UNIFI_SITE env var
 }
\ No newline at end of file
@@ -0,0 +1,8 @@
+# import from provider configured site
+terraform import unifi_ap_group.lobby 5fe6261995fe130013456a36
+
+# import from another site
+terraform import unifi_ap_group.lobby bfa2l6i7:5fe6261995fe130013456a36
+
+# import by name
+terraform import unifi_ap_group.lobby default:name=lobby
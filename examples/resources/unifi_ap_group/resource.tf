@@ -0,0 +1,18 @@
+resource "unifi_ap_group" "lobby" {
+  name = "lobby"
+
+  device_macs = [
+    "01:23:45:67:89:ab",
+    "01:23:45:67:89:ac",
+  ]
+}
+
+resource "unifi_wlan" "guest" {
+  name     = "guest"
+  security = "open"
+  is_guest = true
+
+  network_id    = var.guest_network_id
+  ap_group_ids  = [unifi_ap_group.lobby.id]
+  user_group_id = var.user_group_id
+}
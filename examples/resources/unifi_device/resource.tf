@@ -20,6 +20,9 @@ resource "unifi_device" "us_24_poe" {
   # manual import is the safest way to add a device
   mac = "01:23:45:67:89:AB"
 
+  # adopt the switch if it is still pending adoption
+  adopt = true
+
   name = "Switch with POE"
 
   port_override {
@@ -33,4 +36,31 @@ resource "unifi_device" "us_24_poe" {
     name            = "disabled"
     port_profile_id = data.unifi_port_profile.disabled.id
   }
+
+  port_override {
+    number            = 3
+    name              = "camera"
+    poe_mode          = "auto"
+    native_network_id = var.native_network_id
+
+    # force the link to 100 Mbps full duplex
+    speed       = 100
+    full_duplex = true
+
+    isolation                    = true
+    storm_control_broadcast_rate = 1000
+    egress_rate_limit_kbps       = 10000
+  }
+
+  # ports 5 and 6 form a LACP link to a NAS
+  aggregate {
+    port      = 5
+    num_ports = 2
+  }
+
+  # port 8 receives a copy of the traffic of port 3
+  mirror {
+    port        = 8
+    source_port = 3
+  }
 }
@@ -0,0 +1,5 @@
+# import using the ID
+terraform import unifi_firewall_group.can_print 5fe6261995fe130013456a36
+
+# import using the name from another site
+terraform import unifi_firewall_group.can_print bfa2l6i7:name=can-print
@@ -1,2 +1,5 @@
 # import using the ID from the controller API/UI
 terraform import unifi_firewall_rule.my_rule 5f7080eb6b8969064f80494f
+
+# import using the name
+terraform import unifi_firewall_rule.my_rule "name=drop all"
@@ -0,0 +1,5 @@
+# import the rules of a ruleset from rule index 2000
+terraform import unifi_firewall_ruleset.lan_in LAN_IN
+
+# import the rules of a ruleset in a site from rule index 4000
+terraform import unifi_firewall_ruleset.lan_in default:LAN_IN/4000
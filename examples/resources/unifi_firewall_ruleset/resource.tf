@@ -0,0 +1,24 @@
+variable "iot_network_id" {
+  type = string
+}
+
+resource "unifi_firewall_ruleset" "lan_in" {
+  ruleset = "LAN_IN"
+
+  rule {
+    name     = "allow established"
+    action   = "accept"
+    protocol = "all"
+
+    state_established = true
+    state_related     = true
+  }
+
+  rule {
+    name     = "drop iot to lan"
+    action   = "drop"
+    protocol = "all"
+
+    src_network_id = var.iot_network_id
+  }
+}
@@ -0,0 +1,5 @@
+# import using the ID
+terraform import unifi_port_profile.poe_disabled 5fe6261995fe130013456a36
+
+# import using the name
+terraform import unifi_port_profile.poe_disabled "name=POE Disabled"
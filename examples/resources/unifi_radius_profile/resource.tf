@@ -0,0 +1,23 @@
+variable "radius_secret" {
+  type      = string
+  sensitive = true
+}
+
+resource "unifi_radius_profile" "corp" {
+  name = "corp"
+
+  auth_server {
+    ip     = "192.168.1.10"
+    secret = var.radius_secret
+  }
+
+  accounting_enabled = true
+
+  acct_server {
+    ip     = "192.168.1.10"
+    secret = var.radius_secret
+  }
+
+  vlan_enabled   = true
+  vlan_wlan_mode = "optional"
+}
@@ -0,0 +1,5 @@
+# import using the ID
+terraform import unifi_static_route.nexthop 5fe6261995fe130013456a36
+
+# import using the name
+terraform import unifi_static_route.nexthop "name=basic nexthop"
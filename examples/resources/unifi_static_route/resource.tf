@@ -20,3 +20,11 @@ resource "unifi_static_route" "interface" {
   distance  = 1
   interface = "WAN2"
 }
+
+resource "unifi_static_route" "nexthop_v6" {
+  type     = "nexthop-route"
+  network  = "2001:db8:100::/48"
+  name     = "ipv6 nexthop"
+  distance = 1
+  next_hop = "2001:db8::1"
+}
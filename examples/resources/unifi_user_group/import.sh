@@ -1,2 +1,5 @@
 # import using the ID
-terraform import unifi_user_group.wifi 5fe6261995fe130013456a36
\ No newline at end of file
+terraform import unifi_user_group.wifi 5fe6261995fe130013456a36
+
+# import using the name
+terraform import unifi_user_group.wifi name=wifi
@@ -3,3 +3,6 @@ terraform import unifi_wlan.mywlan 5dc28e5e9106d105bdc87217
 
 # import from another site
 terraform import unifi_wlan.mywlan bfa2l6i7:5dc28e5e9106d105bdc87217
+
+# import by name
+terraform import unifi_wlan.mywlan name=Guest
@@ -28,3 +28,34 @@ resource "unifi_wlan" "wifi" {
   ap_group_ids  = [data.unifi_ap_group.default.id]
   user_group_id = data.unifi_user_group.default.id
 }
+
+resource "unifi_network" "iot" {
+  name    = "iot"
+  purpose = "corporate"
+
+  subnet       = "10.0.1.1/24"
+  vlan_id      = var.vlan_id + 1
+  dhcp_start   = "10.0.1.6"
+  dhcp_stop    = "10.0.1.254"
+  dhcp_enabled = true
+}
+
+# private pre-shared keys require controller version >= 7.3
+resource "unifi_wlan" "ppsk" {
+  name     = "myssid-ppsk"
+  security = "wpapsk"
+
+  network_id    = unifi_network.vlan.id
+  ap_group_ids  = [data.unifi_ap_group.default.id]
+  user_group_id = data.unifi_user_group.default.id
+
+  private_preshared_keys {
+    passphrase = "lan-passphrase"
+    network_id = unifi_network.vlan.id
+  }
+
+  private_preshared_keys {
+    passphrase = "iot-passphrase"
+    network_id = unifi_network.iot.id
+  }
+}
@@ -0,0 +1,512 @@
+package fakeunifi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type meta struct {
+	RC      string `json:"rc"`
+	Message string `json:"msg,omitempty"`
+
+	ServerVersion string `json:"server_version,omitempty"`
+	Up            bool   `json:"up,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeData writes a response in the envelope used by the v1 API.
+func writeData(w http.ResponseWriter, data []Object) {
+	if data == nil {
+		data = []Object{}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Meta meta     `json:"meta"`
+		Data []Object `json:"data"`
+	}{meta{RC: "ok"}, data})
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, struct {
+		Meta meta     `json:"meta"`
+		Data []Object `json:"data"`
+	}{meta{RC: "error", Message: msg}, []Object{}})
+}
+
+func decodeObject(r *http.Request) (Object, error) {
+	o := Object{}
+	if r.Body == nil {
+		return o, nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(&o); err != nil {
+		return nil, fmt.Errorf("unable to decode body: %w", err)
+	}
+	return o, nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests[r.Method+" "+r.URL.Path]++
+
+	switch r.URL.Path {
+	case "/":
+		// classic controllers redirect the root, UniFi OS consoles return a 200
+		http.Redirect(w, r, "/manage", http.StatusFound)
+		return
+	case "/status":
+		writeJSON(w, http.StatusOK, struct {
+			Meta meta     `json:"meta"`
+			Data []Object `json:"data"`
+		}{meta{RC: "ok", Up: true, ServerVersion: s.version}, []Object{}})
+		return
+	case "/api/login":
+		s.handleLogin(w, r)
+		return
+	case "/api/logout":
+		if c, err := r.Cookie(sessionCookie); err == nil {
+			delete(s.sessions, c.Value)
+		}
+		writeData(w, nil)
+		return
+	}
+
+	if c, err := r.Cookie(sessionCookie); err != nil || !s.sessions[c.Value] {
+		writeError(w, http.StatusUnauthorized, "api.err.LoginRequired")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 3 && parts[0] == "api" && parts[1] == "self" && parts[2] == "sites":
+		s.handleSites(w, r)
+	case len(parts) >= 4 && parts[0] == "api" && parts[1] == "s":
+		st := s.site(parts[2])
+		if st == nil {
+			writeError(w, http.StatusBadRequest, "api.err.NoSiteContext")
+			return
+		}
+		s.handleSite(w, r, st, parts[3:])
+	case len(parts) >= 5 && parts[0] == "v2" && parts[1] == "api" && parts[2] == "site":
+		st := s.site(parts[3])
+		if st == nil {
+			writeError(w, http.StatusBadRequest, "api.err.NoSiteContext")
+			return
+		}
+		s.handleV2(w, r, st, parts[4:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+
+	var body struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil ||
+		body.Username != s.username || body.Password != s.password {
+		writeError(w, http.StatusBadRequest, "api.err.Invalid")
+		return
+	}
+
+	session := s.newID()
+	s.sessions[session] = true
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: session, Path: "/"})
+	writeData(w, nil)
+}
+
+func (s *Server) handleSites(w http.ResponseWriter, r *http.Request) {
+	data := make([]Object, 0, len(s.sites))
+	for _, st := range s.sites {
+		data = append(data, st.object())
+	}
+	writeData(w, data)
+}
+
+func (st *site) object() Object {
+	return Object{
+		"_id":  st.id,
+		"name": st.name,
+		"desc": st.description,
+	}
+}
+
+func (s *Server) handleSite(w http.ResponseWriter, r *http.Request, st *site, parts []string) {
+	switch parts[0] {
+	case "rest":
+		s.handleREST(w, r, st, parts[1:])
+	case "stat":
+		s.handleStat(w, r, st, parts[1:])
+	case "group":
+		s.handleGroup(w, r, st, parts[1:])
+	case "cmd":
+		s.handleCmd(w, r, st, parts[1:])
+	case "get", "set":
+		s.handleSetting(w, r, st, parts)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleREST serves the generic /rest/<collection>[/<id>] endpoints.
+func (s *Server) handleREST(w http.ResponseWriter, r *http.Request, st *site, parts []string) {
+	if len(parts) == 0 || len(parts) > 2 {
+		http.NotFound(w, r)
+		return
+	}
+	collection := parts[0]
+
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			writeData(w, copyObjects(st.objects[collection]))
+		case http.MethodPost:
+			o, err := decodeObject(r)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			delete(o, "_id")
+			if msg := st.conflict(collection, "", o); msg != "" {
+				writeError(w, http.StatusBadRequest, msg)
+				return
+			}
+			writeData(w, []Object{copyObject(st.create(s.newID(), collection, o))})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "api.err.InvalidMethod")
+		}
+		return
+	}
+
+	id := parts[1]
+	switch r.Method {
+	case http.MethodGet:
+		// the controller returns an empty list for unknown IDs
+		var data []Object
+		if _, o := st.find(collection, id); o != nil {
+			data = append(data, copyObject(o))
+		}
+		writeData(w, data)
+	case http.MethodPut:
+		o, err := decodeObject(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if msg := st.conflict(collection, id, o); msg != "" {
+			writeError(w, http.StatusBadRequest, msg)
+			return
+		}
+		updated := st.update(collection, id, o)
+		if updated == nil {
+			http.NotFound(w, r)
+			return
+		}
+		writeData(w, []Object{copyObject(updated)})
+
+		// a connected device provisions port changes until it is next polled
+		if _, ok := o["port_overrides"]; ok && collection == "device" &&
+			fmt.Sprint(updated["state"]) == fmt.Sprint(deviceStateConnected) {
+			updated["state"] = deviceStateProvisioning
+		}
+	case http.MethodDelete:
+		if !st.delete(collection, id) {
+			http.NotFound(w, r)
+			return
+		}
+		writeData(w, nil)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "api.err.InvalidMethod")
+	}
+}
+
+// handleStat serves the read only /stat endpoints, devices and clients are
+// looked up by MAC address.
+func (s *Server) handleStat(w http.ResponseWriter, r *http.Request, st *site, parts []string) {
+	if r.Method != http.MethodGet || len(parts) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case parts[0] == "sysinfo":
+		writeData(w, []Object{{"version": s.version, "name": st.name}})
+	case parts[0] == "device" && len(parts) == 1:
+		writeData(w, copyObjects(st.objects["device"]))
+		for _, dev := range st.objects["device"] {
+			switch fmt.Sprint(dev["state"]) {
+			case fmt.Sprint(deviceStateAdopting), fmt.Sprint(deviceStateProvisioning):
+				s.cfgVersion++
+				dev["state"] = deviceStateConnected
+				dev["cfgversion"] = fmt.Sprintf("%016x", s.cfgVersion)
+			}
+		}
+	case (parts[0] == "device" || parts[0] == "user") && len(parts) == 2:
+		writeData(w, copyObjects(st.findBy(parts[0], "mac", parts[1])))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleGroup serves the batch create endpoint used for clients.
+func (s *Server) handleGroup(w http.ResponseWriter, r *http.Request, st *site, parts []string) {
+	if r.Method != http.MethodPost || len(parts) != 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	var body struct {
+		Objects []struct {
+			Data Object `json:"data"`
+		} `json:"objects"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	type result struct {
+		Meta meta     `json:"meta"`
+		Data []Object `json:"data"`
+	}
+	results := []result{}
+	for _, o := range body.Objects {
+		if mac, ok := o.Data["mac"].(string); ok && len(st.findBy(parts[0], "mac", mac)) > 0 {
+			results = append(results, result{Meta: meta{RC: "error", Message: "api.err.MacUsed"}, Data: []Object{}})
+			continue
+		}
+		delete(o.Data, "_id")
+		created := copyObject(st.create(s.newID(), parts[0], o.Data))
+		results = append(results, result{Meta: meta{RC: "ok"}, Data: []Object{created}})
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Meta meta     `json:"meta"`
+		Data []result `json:"data"`
+	}{meta{RC: "ok"}, results})
+}
+
+func (s *Server) handleCmd(w http.ResponseWriter, r *http.Request, st *site, parts []string) {
+	if r.Method != http.MethodPost || len(parts) != 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := decodeObject(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	cmd, _ := body["cmd"].(string)
+
+	switch parts[0] {
+	case "stamgr":
+		s.handleStamgr(w, st, cmd, body)
+	case "sitemgr":
+		s.handleSitemgr(w, st, cmd, body)
+	case "devmgr":
+		s.handleDevmgr(w, st, cmd, body)
+	default:
+		writeError(w, http.StatusBadRequest, "api.err.UnknownCommand")
+	}
+}
+
+func (s *Server) handleStamgr(w http.ResponseWriter, st *site, cmd string, body Object) {
+	switch cmd {
+	case "block-sta", "unblock-sta":
+		mac, _ := body["mac"].(string)
+		users := st.findBy("user", "mac", mac)
+		for _, u := range users {
+			u["blocked"] = cmd == "block-sta"
+		}
+		writeData(w, copyObjects(users))
+	case "forget-sta":
+		macs, _ := body["macs"].([]interface{})
+		var forgotten []Object
+		for _, m := range macs {
+			mac, _ := m.(string)
+			for _, u := range st.findBy("user", "mac", mac) {
+				st.delete("user", u["_id"].(string))
+				forgotten = append(forgotten, u)
+			}
+		}
+		writeData(w, copyObjects(forgotten))
+	default:
+		writeError(w, http.StatusBadRequest, "api.err.UnknownCommand")
+	}
+}
+
+// These are the values of the state field of a device used by the fake.
+const (
+	deviceStateConnected       = 1
+	deviceStatePendingAdoption = 2
+	deviceStateProvisioning    = 5
+	deviceStateAdopting        = 7
+)
+
+func (s *Server) handleDevmgr(w http.ResponseWriter, st *site, cmd string, body Object) {
+	mac, _ := body["mac"].(string)
+	devices := st.findBy("device", "mac", mac)
+	if len(devices) == 0 {
+		writeError(w, http.StatusBadRequest, "api.err.UnknownDevice")
+		return
+	}
+	dev := devices[0]
+
+	switch cmd {
+	case "adopt":
+		if fmt.Sprint(dev["state"]) != fmt.Sprint(deviceStatePendingAdoption) {
+			writeError(w, http.StatusBadRequest, "api.err.DeviceNotPending")
+			return
+		}
+		// adoption completes the next time the device is polled
+		dev["state"] = deviceStateAdopting
+		dev["adopted"] = true
+		writeData(w, nil)
+	default:
+		writeError(w, http.StatusBadRequest, "api.err.UnknownCommand")
+	}
+}
+
+func (s *Server) handleSitemgr(w http.ResponseWriter, st *site, cmd string, body Object) {
+	desc, _ := body["desc"].(string)
+
+	switch cmd {
+	case "add-site":
+		// the controller generates a short random name for new sites
+		id := s.newID()
+		added := s.addSite(id[len(id)-8:], desc)
+		writeData(w, []Object{added.object()})
+	case "update-site":
+		st.description = desc
+		writeData(w, []Object{st.object()})
+	case "delete-site":
+		id, _ := body["site"].(string)
+		for i, existing := range s.sites {
+			if existing.id == id {
+				s.sites = append(s.sites[:i], s.sites[i+1:]...)
+				writeData(w, nil)
+				return
+			}
+		}
+		writeError(w, http.StatusBadRequest, "api.err.IdInvalid")
+	case "delete-device":
+		mac, _ := body["mac"].(string)
+		devices := st.findBy("device", "mac", mac)
+		if len(devices) == 0 {
+			writeError(w, http.StatusBadRequest, "api.err.UnknownDevice")
+			return
+		}
+		st.delete("device", devices[0]["_id"].(string))
+		writeData(w, nil)
+	default:
+		writeError(w, http.StatusBadRequest, "api.err.UnknownCommand")
+	}
+}
+
+// handleSetting serves get/setting[/<key>] and set/setting/<key>.
+func (s *Server) handleSetting(w http.ResponseWriter, r *http.Request, st *site, parts []string) {
+	if len(parts) < 2 || parts[1] != "setting" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case parts[0] == "get" && len(parts) == 2:
+		data := []Object{}
+		for _, o := range st.settings {
+			data = append(data, copyObject(o))
+		}
+		writeData(w, data)
+	case parts[0] == "get" && len(parts) == 3:
+		writeData(w, []Object{copyObject(s.setting(st, parts[2]))})
+	case parts[0] == "set" && len(parts) == 3:
+		o, err := decodeObject(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		existing := s.setting(st, parts[2])
+		for k, v := range o {
+			existing[k] = v
+		}
+		existing["key"] = parts[2]
+		existing["site_id"] = st.id
+		writeData(w, []Object{copyObject(existing)})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleV2 serves the v2 API collections, which use plain JSON bodies instead
+// of the meta and data envelope.
+func (s *Server) handleV2(w http.ResponseWriter, r *http.Request, st *site, parts []string) {
+	if len(parts) == 0 || len(parts) > 2 {
+		http.NotFound(w, r)
+		return
+	}
+	collection := parts[0]
+
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, copyObjects(st.objects[collection]))
+		case http.MethodPost:
+			o, err := decodeObject(r)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			delete(o, "_id")
+			created := st.create(s.newID(), collection, o)
+			// v2 objects are not scoped with a site ID
+			delete(created, "site_id")
+			writeJSON(w, http.StatusOK, copyObject(created))
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "api.err.InvalidMethod")
+		}
+		return
+	}
+
+	id := parts[1]
+	switch r.Method {
+	case http.MethodPut:
+		o, err := decodeObject(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if msg := st.conflict(collection, id, o); msg != "" {
+			writeError(w, http.StatusBadRequest, msg)
+			return
+		}
+		updated := st.update(collection, id, o)
+		if updated == nil {
+			http.NotFound(w, r)
+			return
+		}
+		delete(updated, "site_id")
+		writeJSON(w, http.StatusOK, copyObject(updated))
+	case http.MethodDelete:
+		if !st.delete(collection, id) {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "api.err.InvalidMethod")
+	}
+}
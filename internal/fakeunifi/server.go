@@ -0,0 +1,298 @@
+// Package fakeunifi implements an in-process fake of the Unifi controller API
+// for tests that should not require a real controller.
+//
+// The fake serves the classic (non UniFi OS) path style. Objects are stored in
+// memory as plain JSON objects, so any REST collection is supported, but no
+// validation is done beyond what is required to keep state consistent.
+package fakeunifi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultUsername and DefaultPassword are the credentials accepted by a new server.
+	DefaultUsername = "admin"
+	DefaultPassword = "admin"
+
+	// DefaultVersion is the controller version reported by a new server.
+	DefaultVersion = "6.0.43"
+
+	sessionCookie = "unifises"
+)
+
+// Object is a controller object as decoded from JSON.
+type Object = map[string]interface{}
+
+// Server is a fake controller backed by an httptest.Server.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	username string
+	password string
+	version  string
+	nextID   int
+	sessions map[string]bool
+	sites    []*site
+	requests map[string]int
+
+	// cfgVersion numbers the configurations provisioned to devices
+	cfgVersion int
+}
+
+type site struct {
+	id          string
+	name        string
+	description string
+
+	// objects holds the REST collections in creation order
+	objects  map[string][]Object
+	settings map[string]Object
+}
+
+// NewServer starts a fake controller with a default site and the default
+// credentials and version. Callers should Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		username: DefaultUsername,
+		password: DefaultPassword,
+		version:  DefaultVersion,
+		sessions: map[string]bool{},
+		requests: map[string]int{},
+	}
+	s.addSite("default", "Default")
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// SetCredentials changes the credentials accepted by the login endpoint.
+func (s *Server) SetCredentials(username, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.username = username
+	s.password = password
+}
+
+// SetVersion changes the version reported by the controller.
+func (s *Server) SetVersion(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version = version
+}
+
+// ExpireSessions invalidates all sessions, as if the controller restarted.
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = map[string]bool{}
+}
+
+// RequestCount returns the number of requests served for the method and path.
+func (s *Server) RequestCount(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[method+" "+path]
+}
+
+// AddObject stores a copy of obj in the collection of the site (for example
+// "device" for adopted devices) and returns its ID. obj can be any value that
+// marshals to a JSON object.
+func (s *Server) AddObject(siteName, collection string, obj interface{}) (string, error) {
+	o, err := toObject(obj)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.site(siteName)
+	if st == nil {
+		return "", fmt.Errorf("site %q not found", siteName)
+	}
+	return st.create(s.newID(), collection, o)["_id"].(string), nil
+}
+
+// Object returns a copy of the object with the ID from the collection of the
+// site, or nil if it does not exist.
+func (s *Server) Object(siteName, collection, id string) Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.site(siteName)
+	if st == nil {
+		return nil
+	}
+	if _, o := st.find(collection, id); o != nil {
+		return copyObject(o)
+	}
+	return nil
+}
+
+// Objects returns copies of all objects in the collection of the site.
+func (s *Server) Objects(siteName, collection string) []Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.site(siteName)
+	if st == nil {
+		return nil
+	}
+	return copyObjects(st.objects[collection])
+}
+
+func (s *Server) newID() string {
+	s.nextID++
+	// mimic the format of MongoDB object IDs
+	return fmt.Sprintf("%024x", s.nextID)
+}
+
+func (s *Server) addSite(name, description string) *site {
+	st := &site{
+		id:          s.newID(),
+		name:        name,
+		description: description,
+		objects:     map[string][]Object{},
+		settings:    map[string]Object{},
+	}
+	s.sites = append(s.sites, st)
+	return st
+}
+
+func (s *Server) site(name string) *site {
+	for _, st := range s.sites {
+		if st.name == name {
+			return st
+		}
+	}
+	return nil
+}
+
+func (st *site) find(collection, id string) (int, Object) {
+	for i, o := range st.objects[collection] {
+		if o["_id"] == id {
+			return i, o
+		}
+	}
+	return -1, nil
+}
+
+func (st *site) findBy(collection, key, value string) []Object {
+	var found []Object
+	for _, o := range st.objects[collection] {
+		if v, ok := o[key].(string); ok && strings.EqualFold(v, value) {
+			found = append(found, o)
+		}
+	}
+	return found
+}
+
+func (st *site) create(id, collection string, o Object) Object {
+	o["_id"] = id
+	o["site_id"] = st.id
+	st.objects[collection] = append(st.objects[collection], o)
+	return o
+}
+
+// update merges the fields of o into the existing object, like the controller
+// does for a PUT.
+func (st *site) update(collection, id string, o Object) Object {
+	_, existing := st.find(collection, id)
+	if existing == nil {
+		return nil
+	}
+	for k, v := range o {
+		existing[k] = v
+	}
+	existing["_id"] = id
+	existing["site_id"] = st.id
+	return existing
+}
+
+// conflict returns the error the controller reports when saving o, with the
+// fields of the existing object with the ID if any, would clash with another
+// object of the collection, or an empty string.
+func (st *site) conflict(collection, id string, o Object) string {
+	merged := Object{}
+	if _, existing := st.find(collection, id); existing != nil {
+		for k, v := range existing {
+			merged[k] = v
+		}
+	}
+	for k, v := range o {
+		merged[k] = v
+	}
+
+	switch collection {
+	case "firewallrule":
+		// rule indexes are unique within a ruleset
+		for _, other := range st.objects[collection] {
+			if other["_id"] == id {
+				continue
+			}
+			if fmt.Sprint(other["ruleset"]) == fmt.Sprint(merged["ruleset"]) &&
+				fmt.Sprint(other["rule_index"]) == fmt.Sprint(merged["rule_index"]) {
+				return "api.err.FirewallRuleIndexExisted"
+			}
+		}
+	}
+	return ""
+}
+
+func (st *site) delete(collection, id string) bool {
+	i, o := st.find(collection, id)
+	if o == nil {
+		return false
+	}
+	st.objects[collection] = append(st.objects[collection][:i], st.objects[collection][i+1:]...)
+	return true
+}
+
+// setting returns the setting with the key, settings always exist on a
+// controller so they are created with only the key on first use.
+func (s *Server) setting(st *site, key string) Object {
+	if o, ok := st.settings[key]; ok {
+		return o
+	}
+	o := Object{
+		"_id":     s.newID(),
+		"key":     key,
+		"site_id": st.id,
+	}
+	st.settings[key] = o
+	return o
+}
+
+func toObject(v interface{}) (Object, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var o Object
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func copyObject(o Object) Object {
+	c, err := toObject(o)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func copyObjects(objects []Object) []Object {
+	c := make([]Object, 0, len(objects))
+	for _, o := range objects {
+		c = append(c, copyObject(o))
+	}
+	return c
+}
@@ -0,0 +1,169 @@
+package fakeunifi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/paultyng/go-unifi/unifi"
+)
+
+func newTestClient(t *testing.T, s *Server) *unifi.Client {
+	t.Helper()
+
+	c := &unifi.Client{}
+	if err := c.SetBaseURL(s.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Login(context.Background(), DefaultUsername, DefaultPassword); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestServer_login(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	c := newTestClient(t, s)
+	if c.Version() != DefaultVersion {
+		t.Fatalf("expected version %q, got %q", DefaultVersion, c.Version())
+	}
+
+	wrong := &unifi.Client{}
+	if err := wrong.SetBaseURL(s.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := wrong.Login(context.Background(), DefaultUsername, "wrong"); err == nil {
+		t.Fatal("expected error for wrong password")
+	}
+
+	s.ExpireSessions()
+	if _, err := c.ListNetwork(context.Background(), "default"); err == nil {
+		t.Fatal("expected error for expired session")
+	}
+}
+
+func TestServer_rest(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+
+	c := newTestClient(t, s)
+
+	created, err := c.CreateUserGroup(ctx, "default", &unifi.UserGroup{Name: "test", QOSRateMaxDown: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" {
+		t.Fatal("expected an ID to be assigned")
+	}
+
+	created.Name = "renamed"
+	if _, err := c.UpdateUserGroup(ctx, "default", created); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := c.GetUserGroup(ctx, "default", created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Name != "renamed" || read.QOSRateMaxDown != 100 {
+		t.Fatalf("unexpected user group %#v", read)
+	}
+
+	if err := c.DeleteUserGroup(ctx, "default", created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetUserGroup(ctx, "default", created.ID); err == nil {
+		t.Fatal("expected not found error")
+	} else if _, ok := err.(*unifi.NotFoundError); !ok {
+		t.Fatalf("expected not found error, got %s", err)
+	}
+}
+
+func TestServer_devices(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+
+	id, err := s.AddObject("default", "device", unifi.Device{MAC: "00:11:22:33:44:55", Name: "switch"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestClient(t, s)
+
+	d, err := c.GetDeviceByMAC(ctx, "default", "00:11:22:33:44:55")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.ID != id {
+		t.Fatalf("expected device %q, got %q", id, d.ID)
+	}
+
+	d.Name = "renamed"
+	if _, err := c.UpdateDevice(ctx, "default", d); err != nil {
+		t.Fatal(err)
+	}
+	if name := s.Object("default", "device", id)["name"]; name != "renamed" {
+		t.Fatalf("expected device to be renamed, got %q", name)
+	}
+}
+
+func TestServer_firewallRuleIndex(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+
+	c := newTestClient(t, s)
+
+	first, err := c.CreateFirewallRule(ctx, "default", &unifi.FirewallRule{Name: "first", Ruleset: "LAN_IN", RuleIndex: 2000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateFirewallRule(ctx, "default", &unifi.FirewallRule{Name: "other", Ruleset: "WAN_IN", RuleIndex: 2000}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateFirewallRule(ctx, "default", &unifi.FirewallRule{Name: "duplicate", Ruleset: "LAN_IN", RuleIndex: 2000}); err == nil {
+		t.Fatal("expected an error for a duplicate rule index")
+	}
+
+	second, err := c.CreateFirewallRule(ctx, "default", &unifi.FirewallRule{Name: "second", Ruleset: "LAN_IN", RuleIndex: 2001})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.RuleIndex = first.RuleIndex
+	if _, err := c.UpdateFirewallRule(ctx, "default", second); err == nil {
+		t.Fatal("expected an error when moving to a used rule index")
+	}
+	if _, err := c.UpdateFirewallRule(ctx, "default", first); err != nil {
+		t.Fatalf("expected updating a rule in place to succeed: %s", err)
+	}
+}
+
+func TestServer_sites(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer()
+	defer s.Close()
+
+	c := newTestClient(t, s)
+
+	sites, err := c.CreateSite(ctx, "test site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sites) != 1 || sites[0].Description != "test site" {
+		t.Fatalf("unexpected sites %#v", sites)
+	}
+
+	if _, err := c.CreateNetwork(ctx, sites[0].Name, &unifi.Network{Name: "lan"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.DeleteSite(ctx, sites[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetSite(ctx, sites[0].ID); err == nil {
+		t.Fatal("expected site to be deleted")
+	}
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIKeyTransport(t *testing.T) {
+	var gotPaths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.URL.Path)
+		if actual := r.Header.Get(apiKeyHeader); actual != "test-key" {
+			t.Errorf("expected API key header %q, got %q", "test-key", actual)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	hc := &http.Client{
+		Transport: &apiKeyTransport{
+			apiKey: "test-key",
+			next:   http.DefaultTransport,
+		},
+	}
+
+	for _, c := range []struct {
+		method string
+		path   string
+		sent   bool
+	}{
+		{"POST", "/api/auth/login", false},
+		{"POST", "/api/login", false},
+		{"GET", "/proxy/network/status", true},
+		{"POST", "/proxy/network/api/s/default/rest/networkconf", true},
+	} {
+		t.Run(c.method+" "+c.path, func(t *testing.T) {
+			gotPaths = nil
+
+			req, err := http.NewRequest(c.method, srv.URL+c.path, strings.NewReader("{}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := hc.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", resp.StatusCode)
+			}
+			if sent := len(gotPaths) > 0; sent != c.sent {
+				t.Fatalf("expected request sent to be %t, got %t", c.sent, sent)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// capability is the range of controller versions supporting a feature.
+type capability struct {
+	// minVersion is inclusive, nil means there is no lower bound
+	minVersion *version.Version
+	// maxVersion is exclusive, nil means there is no upper bound
+	maxVersion *version.Version
+}
+
+func (c capability) supportedBy(v *version.Version) bool {
+	if c.minVersion != nil && v.LessThan(c.minVersion) {
+		return false
+	}
+	if c.maxVersion != nil && !v.LessThan(c.maxVersion) {
+		return false
+	}
+	return true
+}
+
+func (c capability) String() string {
+	parts := []string{}
+	if c.minVersion != nil {
+		parts = append(parts, ">= "+c.minVersion.String())
+	}
+	if c.maxVersion != nil {
+		parts = append(parts, "< "+c.maxVersion.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (c capability) check(v *version.Version, name string) error {
+	if c.supportedBy(v) {
+		return nil
+	}
+	return fmt.Errorf("%s is not supported on controller version %q, it requires controller version %s", name, v, c)
+}
+
+var (
+	capabilityV5 = capability{minVersion: controllerV5, maxVersion: controllerV6}
+	capabilityV6 = capability{minVersion: controllerV6}
+
+	// private pre-shared keys were added in UniFi Network 7.3
+	capabilityPPSK = capability{minVersion: version.Must(version.NewVersion("7.3.0"))}
+)
+
+// typeCapabilities lists the resources and data sources that are only
+// supported on some controller versions, keyed on type name. Resources are
+// checked when planning by customizeDiffCapabilities, data sources check
+// their type in their read function.
+var typeCapabilities = map[string]capability{
+	"unifi_ap_group":   capabilityV6,
+	"unifi_wlan":       {minVersion: controllerV5},
+	"unifi_wlan_group": capabilityV5,
+}
+
+// attributeCapabilities lists the resource attributes that are only supported
+// on some controller versions, keyed on type name and then attribute name.
+var attributeCapabilities = map[string]map[string]capability{
+	"unifi_wlan": {
+		"ap_group_ids":           capabilityV6,
+		"network_id":             capabilityV6,
+		"wlan_band":              capabilityV6,
+		"private_preshared_keys": capabilityPPSK,
+		"wpa3_support":           capabilityV6,
+		"wpa3_transition":        capabilityV6,
+		"minimum_rssi":           capabilityV6,
+		"vlan_id":                capabilityV5,
+		"wlan_group_id":          capabilityV5,
+	},
+}
+
+// supportsAttribute reports whether an attribute should be sent to a
+// controller of the given version.
+func supportsAttribute(typeName, attr string, v *version.Version) bool {
+	c, ok := attributeCapabilities[typeName][attr]
+	return !ok || c.supportedBy(v)
+}
+
+// checkCapabilities returns an error if the type, or any of its attributes
+// reported as set by isSet, is not supported by the controller version. isSet
+// can be nil to only check the type.
+func checkCapabilities(typeName string, v *version.Version, isSet func(attr string) bool) error {
+	if c, ok := typeCapabilities[typeName]; ok {
+		if err := c.check(v, typeName); err != nil {
+			return err
+		}
+	}
+
+	attrs := attributeCapabilities[typeName]
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if isSet == nil || !isSet(name) {
+			continue
+		}
+		if err := attrs[name].check(v, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// customizeDiffCapabilities fails the plan if the resource or any of the
+// configured attributes are not supported by the connected controller.
+func customizeDiffCapabilities(typeName string, next schema.CustomizeDiffFunc) schema.CustomizeDiffFunc {
+	_, hasType := typeCapabilities[typeName]
+	_, hasAttrs := attributeCapabilities[typeName]
+	if !hasType && !hasAttrs {
+		return next
+	}
+
+	check := func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		c, ok := meta.(*client)
+		if !ok {
+			return nil
+		}
+
+		v, err := c.ControllerVersion(ctx)
+		if err != nil {
+			return err
+		}
+
+		return checkCapabilities(typeName, v, func(attr string) bool {
+			// values that are not known yet may be empty, they are checked
+			// during apply
+			if !d.NewValueKnown(attr) {
+				return false
+			}
+			_, ok := d.GetOk(attr)
+			return ok
+		})
+	}
+
+	if next == nil {
+		return check
+	}
+	return customdiff.Sequence(check, next)
+}
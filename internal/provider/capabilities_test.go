@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/paultyng/go-unifi/unifi"
+)
+
+func TestCapability(t *testing.T) {
+	for _, c := range []struct {
+		capability capability
+		version    string
+		expected   bool
+	}{
+		{capabilityV6, "6.0.43", true},
+		{capabilityV6, "6.0.0", true},
+		{capabilityV6, "5.14.23", false},
+		{capabilityV5, "5.14.23", true},
+		{capabilityV5, "6.0.43", false},
+		{capabilityV5, "4.3.28", false},
+		{capability{}, "4.3.28", true},
+	} {
+		t.Run(c.capability.String()+" "+c.version, func(t *testing.T) {
+			v := version.Must(version.NewVersion(c.version))
+			if actual := c.capability.supportedBy(v); actual != c.expected {
+				t.Fatalf("expected %t, got %t", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCheckCapabilities(t *testing.T) {
+	v := version.Must(version.NewVersion("6.0.43"))
+	isSet := func(attrs ...string) func(string) bool {
+		return func(attr string) bool {
+			for _, a := range attrs {
+				if a == attr {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	if err := checkCapabilities("unifi_wlan", v, isSet("network_id", "ap_group_ids")); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkCapabilities("unifi_user_group", v, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	err := checkCapabilities("unifi_wlan", v, isSet("network_id", "vlan_id"))
+	if err == nil {
+		t.Fatal("expected an error for vlan_id")
+	}
+	expected := `vlan_id is not supported on controller version "6.0.43", it requires controller version >= 5.0.0, < 6.0.0`
+	if err.Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, err)
+	}
+
+	if err := checkCapabilities("unifi_wlan_group", v, nil); err == nil {
+		t.Fatal("expected an error for unifi_wlan_group")
+	}
+}
+
+func TestCapabilities_fake(t *testing.T) {
+	c, _ := newFakeClient(t)
+
+	err := testFakePlanError(t, c, "unifi_wlan", map[string]interface{}{
+		"name":          "tfacc",
+		"user_group_id": "000000000000000000000001",
+		"security":      "open",
+		"vlan_id":       10,
+	})
+	if !strings.Contains(err.Error(), "vlan_id is not supported") {
+		t.Fatalf("expected vlan_id to be rejected, got %q", err)
+	}
+
+	// an unknown value may turn out empty, so it is only checked during apply
+	_, err = New("test")().ResourcesMap["unifi_wlan"].Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":          "tfacc",
+		"user_group_id": "000000000000000000000001",
+		"security":      "open",
+		// the value Terraform uses for unknown values
+		"wlan_group_id": "74D93920-ED26-11E3-AC10-0800200C9A66",
+	}), c)
+	if err != nil {
+		t.Fatalf("expected an unknown wlan_group_id to be planned, got %q", err)
+	}
+
+	c, s := newFakeClient(t)
+	s.SetVersion("5.14.23")
+
+	err = testFakePlanError(t, c, "unifi_ap_group", map[string]interface{}{
+		"name": "tfacc",
+	})
+	if !strings.Contains(err.Error(), "unifi_ap_group is not supported") {
+		t.Fatalf("expected unifi_ap_group to be rejected, got %q", err)
+	}
+
+	err = testFakePlanError(t, c, "unifi_wlan", map[string]interface{}{
+		"name":          "tfacc",
+		"user_group_id": "000000000000000000000001",
+		"security":      "open",
+		"wlan_band":     "5g",
+	})
+	if !strings.Contains(err.Error(), "wlan_band is not supported") {
+		t.Fatalf("expected wlan_band to be rejected, got %q", err)
+	}
+}
+
+func TestCapabilities_fake_wlanFields(t *testing.T) {
+	for _, tc := range []struct {
+		version  string
+		expected []string
+		omitted  []string
+	}{
+		{"5.14.23", []string{"vlan", "vlan_enabled", "wlangroup_id"}, []string{"networkconf_id", "ap_group_ids", "wlan_band"}},
+		{"6.0.43", []string{"networkconf_id", "ap_group_ids", "wlan_band"}, []string{"vlan", "vlan_enabled", "wlangroup_id"}},
+	} {
+		t.Run(tc.version, func(t *testing.T) {
+			c, s := newFakeClient(t)
+			s.SetVersion(tc.version)
+
+			wlan, err := c.c.CreateWLAN(context.Background(), "default", &unifi.WLAN{
+				Name:        "tfacc",
+				NetworkID:   "000000000000000000000001",
+				ApGroupIDs:  []string{"000000000000000000000002"},
+				WLANBand:    "both",
+				VLAN:        10,
+				VLANEnabled: true,
+				WLANGroupID: "000000000000000000000003",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// only the fields of the controller version are sent
+			o := s.Object("default", "wlanconf", wlan.ID)
+			for _, f := range tc.expected {
+				if _, ok := o[f]; !ok {
+					t.Errorf("expected %s to be sent", f)
+				}
+			}
+			for _, f := range tc.omitted {
+				if _, ok := o[f]; ok {
+					t.Errorf("expected %s to be left out, got %v", f, o[f])
+				}
+			}
+		})
+	}
+}
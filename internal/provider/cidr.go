@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 
@@ -40,9 +41,6 @@ func cidrZeroBased(cidr string) string {
 	if err != nil {
 		return ""
 	}
-	if len(cidrNet.Mask) == net.IPv6len {
-		return ""
-	}
 
 	return cidrNet.String()
 }
@@ -52,11 +50,77 @@ func cidrOneBased(cidr string) string {
 	if err != nil {
 		return ""
 	}
-	if len(cidrNet.Mask) == net.IPv6len {
-		return ""
-	}
 
-	cidrNet.IP[3]++
+	cidrNet.IP[len(cidrNet.IP)-1]++
 
 	return cidrNet.String()
 }
+
+// ipNormalize returns the canonical form of an IP address or of an address in
+// CIDR notation, keeping any host bits, so that for example 2001:DB8:0::1 and
+// 2001:db8::1 compare equal. Other values are returned unchanged.
+func ipNormalize(v string) string {
+	if ip := net.ParseIP(v); ip != nil {
+		return ip.String()
+	}
+	if ip, cidrNet, err := net.ParseCIDR(v); err == nil {
+		ones, _ := cidrNet.Mask.Size()
+		return fmt.Sprintf("%s/%d", ip, ones)
+	}
+	return v
+}
+
+func ipDiffSuppress(k, old, new string, d *schema.ResourceData) bool {
+	return ipNormalize(old) == ipNormalize(new)
+}
+
+// ipFamily returns 4 or 6 for an IP address or CIDR, or 0 if it is neither.
+func ipFamily(v string) int {
+	ip := net.ParseIP(v)
+	if ip == nil {
+		var err error
+		if ip, _, err = net.ParseCIDR(v); err != nil {
+			return 0
+		}
+	}
+	if ip.To4() != nil {
+		return 4
+	}
+	return 6
+}
+
+func ipv6CIDRValidate(raw interface{}, key string) ([]string, []error) {
+	v, ok := raw.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected string, got %T", raw)}
+	}
+
+	if _, _, err := net.ParseCIDR(v); err != nil {
+		return nil, []error{err}
+	}
+	if ipFamily(v) != 6 {
+		return nil, []error{fmt.Errorf("expected %s to be an IPv6 CIDR, got: %s", key, v)}
+	}
+
+	return nil, nil
+}
+
+// ipCompare compares two IP addresses like bytes.Compare, so IPv4 addresses
+// sort before IPv6 addresses outside of the IPv4-mapped range.
+func ipCompare(a, b net.IP) int {
+	return bytes.Compare(a.To16(), b.To16())
+}
+
+// ipv4Broadcast returns the broadcast address of an IPv4 network, or nil for
+// an IPv6 network.
+func ipv4Broadcast(n *net.IPNet) net.IP {
+	ip := n.IP.To4()
+	if ip == nil || len(n.Mask) != net.IPv4len {
+		return nil
+	}
+	broadcast := make(net.IP, net.IPv4len)
+	for i := range ip {
+		broadcast[i] = ip[i] | ^n.Mask[i]
+	}
+	return broadcast
+}
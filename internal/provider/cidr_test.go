@@ -15,7 +15,11 @@ func TestCIDRValidate(t *testing.T) {
 		{"invalid CIDR address: 500.1.2.3/20", "500.1.2.3/20"},
 		{"invalid CIDR address: 192.1.2.3/500", "192.1.2.3/500"},
 
+		{"invalid CIDR address: 2001:db8::1", "2001:db8::1"},
+		{"invalid CIDR address: 2001:db8::1/129", "2001:db8::1/129"},
+
 		{"", "192.1.2.1/20"},
+		{"", "2001:db8::1/64"},
 	} {
 		t.Run(c.cidr, func(t *testing.T) {
 			_, actualErrs := cidrValidate(c.cidr, "key")
@@ -35,3 +39,108 @@ func TestCIDRValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestCIDRZeroBased(t *testing.T) {
+	for _, c := range []struct {
+		cidr     string
+		expected string
+	}{
+		{"", ""},
+		{"abc", ""},
+		{"192.168.1.1/24", "192.168.1.0/24"},
+		{"192.168.1.0/24", "192.168.1.0/24"},
+		{"10.0.0.0/8", "10.0.0.0/8"},
+		{"2001:db8::1/64", "2001:db8::/64"},
+		{"2001:DB8:0:0:1::/48", "2001:db8::/48"},
+		{"::/0", "::/0"},
+	} {
+		t.Run(c.cidr, func(t *testing.T) {
+			if actual := cidrZeroBased(c.cidr); actual != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCIDROneBased(t *testing.T) {
+	for _, c := range []struct {
+		cidr     string
+		expected string
+	}{
+		{"", ""},
+		{"abc", ""},
+		{"192.168.1.0/24", "192.168.1.1/24"},
+		{"192.168.1.100/24", "192.168.1.1/24"},
+		{"2001:db8::/64", "2001:db8::1/64"},
+		{"2001:DB8::ffff/64", "2001:db8::1/64"},
+	} {
+		t.Run(c.cidr, func(t *testing.T) {
+			if actual := cidrOneBased(c.cidr); actual != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCIDRDiffSuppress(t *testing.T) {
+	for _, c := range []struct {
+		old      string
+		new      string
+		expected bool
+	}{
+		{"192.168.1.0/24", "192.168.1.1/24", true},
+		{"192.168.1.0/24", "192.168.2.0/24", false},
+		{"192.168.1.0/24", "192.168.1.0/25", false},
+		{"2001:db8::/64", "2001:DB8:0::1/64", true},
+		{"2001:db8::/64", "2001:db8:1::/64", false},
+		{"2001:db8::/64", "", false},
+	} {
+		t.Run(c.old+" "+c.new, func(t *testing.T) {
+			if actual := cidrDiffSuppress("key", c.old, c.new, nil); actual != c.expected {
+				t.Fatalf("expected %t, got %t", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIPNormalize(t *testing.T) {
+	for _, c := range []struct {
+		v        string
+		expected string
+	}{
+		{"", ""},
+		{"80", "80"},
+		{"10.0.0.1-10.0.0.9", "10.0.0.1-10.0.0.9"},
+		{"10.0.0.1", "10.0.0.1"},
+		{"10.0.0.1/24", "10.0.0.1/24"},
+		{"2001:DB8:0:0::1", "2001:db8::1"},
+		{"2001:db8:0:0::1/64", "2001:db8::1/64"},
+		{"FE80::1", "fe80::1"},
+	} {
+		t.Run(c.v, func(t *testing.T) {
+			if actual := ipNormalize(c.v); actual != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIPFamily(t *testing.T) {
+	for _, c := range []struct {
+		v        string
+		expected int
+	}{
+		{"", 0},
+		{"abc", 0},
+		{"10.0.0.1", 4},
+		{"10.0.0.0/8", 4},
+		{"2001:db8::1", 6},
+		{"2001:db8::/32", 6},
+	} {
+		t.Run(c.v, func(t *testing.T) {
+			if actual := ipFamily(c.v); actual != c.expected {
+				t.Fatalf("expected %d, got %d", c.expected, actual)
+			}
+		})
+	}
+}
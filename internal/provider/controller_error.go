@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/paultyng/go-unifi/unifi"
+)
+
+type controllerErrorKind int
+
+const (
+	controllerErrorUnknown controllerErrorKind = iota
+	controllerErrorBaseURL
+	controllerErrorTLS
+	controllerErrorLogin
+	controllerErrorVersion
+)
+
+// controllerError is returned when the client is unable to connect to,
+// authenticate with or identify the controller.
+type controllerError struct {
+	kind controllerErrorKind
+	err  error
+}
+
+func (e *controllerError) Error() string {
+	return fmt.Sprintf("%s: %s", e.summary(), e.err)
+}
+
+func (e *controllerError) Unwrap() error {
+	return e.err
+}
+
+func (e *controllerError) summary() string {
+	switch e.kind {
+	case controllerErrorBaseURL:
+		return "Unable to reach the Unifi controller API"
+	case controllerErrorTLS:
+		return "Unable to verify the TLS certificate of the Unifi controller"
+	case controllerErrorLogin:
+		return "Unable to log in to the Unifi controller"
+	case controllerErrorVersion:
+		return "Unable to determine the Unifi controller version"
+	}
+	return "Unable to connect to the Unifi controller"
+}
+
+func (e *controllerError) detail() string {
+	switch e.kind {
+	case controllerErrorBaseURL:
+		return "Check that `api_url` points to the controller without the `/api` path, for example " +
+			"`https://unifi.example.com:8443` or `https://192.168.1.1` for UniFi OS consoles."
+	case controllerErrorTLS:
+		return "If the controller uses a self-signed certificate, add its CA with `ca_cert_file` or `ca_cert_pem`, " +
+			"or pin it with `tls_fingerprint_sha256`. Also check that `api_url` uses the `https` scheme."
+	case controllerErrorLogin:
+		return "Check the `username` and `password` (or `api_key`) of the provider configuration. The user must " +
+			"be a local user, two-factor authentication is not supported."
+	case controllerErrorVersion:
+		return "The controller reported a version the provider does not understand."
+	}
+	return ""
+}
+
+// Diagnostic returns the error as a Terraform diagnostic with remediation hints.
+func (e *controllerError) Diagnostic() diag.Diagnostic {
+	detail := e.err.Error()
+	if hint := e.detail(); hint != "" {
+		detail += "\n\n" + hint
+	}
+	return diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  e.summary(),
+		Detail:   detail,
+	}
+}
+
+// classifyLoginError determines the likely cause of a failed login.
+func classifyLoginError(err error) *controllerError {
+	var (
+		unknownAuthorityErr x509.UnknownAuthorityError
+		hostnameErr         x509.HostnameError
+		certInvalidErr      x509.CertificateInvalidError
+		recordHeaderErr     tls.RecordHeaderError
+		fingerprintErr      *fingerprintMismatchError
+		dnsErr              *net.DNSError
+		opErr               *net.OpError
+		notFoundErr         *unifi.NotFoundError
+		apiErr              *unifi.APIError
+	)
+
+	switch {
+	case errors.As(err, &unknownAuthorityErr),
+		errors.As(err, &hostnameErr),
+		errors.As(err, &certInvalidErr),
+		errors.As(err, &recordHeaderErr),
+		errors.As(err, &fingerprintErr):
+		return &controllerError{kind: controllerErrorTLS, err: err}
+	case errors.As(err, &dnsErr),
+		errors.As(err, &opErr),
+		errors.As(err, &notFoundErr):
+		return &controllerError{kind: controllerErrorBaseURL, err: err}
+	case errors.As(err, &apiErr):
+		return &controllerError{kind: controllerErrorLogin, err: err}
+	}
+	return &controllerError{kind: controllerErrorUnknown, err: err}
+}
+
+// errorDiagnostics converts an error to diagnostics, using the additional
+// detail available for controller errors.
+func errorDiagnostics(err error) diag.Diagnostics {
+	if err == nil {
+		return nil
+	}
+	var ce *controllerError
+	if errors.As(err, &ce) {
+		return diag.Diagnostics{ce.Diagnostic()}
+	}
+	return diag.FromErr(err)
+}
+
+// withControllerErrors wraps the operations of a resource or data source to
+// connect to the controller first. The first API call of a run logs in, so
+// this reports connection failures with errorDiagnostics, whichever operation
+// runs first.
+func withControllerErrors(r *schema.Resource) {
+	if r.CreateContext != nil {
+		r.CreateContext = schema.CreateContextFunc(connectFirst(r.CreateContext))
+	}
+	if r.ReadContext != nil {
+		r.ReadContext = schema.ReadContextFunc(connectFirst(r.ReadContext))
+	}
+	if r.UpdateContext != nil {
+		r.UpdateContext = schema.UpdateContextFunc(connectFirst(r.UpdateContext))
+	}
+	if r.DeleteContext != nil {
+		r.DeleteContext = schema.DeleteContextFunc(connectFirst(r.DeleteContext))
+	}
+}
+
+func connectFirst(next func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		if c, ok := meta.(*client); ok {
+			if _, err := c.ControllerVersion(ctx); err != nil {
+				return errorDiagnostics(err)
+			}
+		}
+		return next(ctx, d, meta)
+	}
+}
@@ -2,8 +2,8 @@
 
 import (
 	"context"
-	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -11,7 +11,10 @@ func dataAPGroup() *schema.Resource {
 	return &schema.Resource{
 		Description: "`unifi_ap_group` data source can be used to retrieve the ID for an AP group by name.",
 
-		Read: dataAPGroupRead,
+		ReadContext: dataAPGroupRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultTimeout),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"id": {
@@ -34,11 +37,15 @@ func dataAPGroup() *schema.Resource {
 	}
 }
 
-func dataAPGroupRead(d *schema.ResourceData, meta interface{}) error {
+func dataAPGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
-	if v := c.ControllerVersion(); !v.GreaterThanOrEqual(controllerV6) {
-		return fmt.Errorf("AP groups are not supported on controller version %q", v)
+	v, err := c.ControllerVersion(ctx)
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	if err := checkCapabilities("unifi_ap_group", v, nil); err != nil {
+		return diag.FromErr(err)
 	}
 
 	name := d.Get("name").(string)
@@ -47,9 +54,9 @@ func dataAPGroupRead(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	groups, err := c.c.ListAPGroup(context.TODO(), site)
+	groups, err := c.c.ListAPGroup(ctx, site)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	for _, g := range groups {
 		if (name == "" && g.HiddenID == "default") || g.Name == name {
@@ -59,5 +66,5 @@ func dataAPGroupRead(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
-	return fmt.Errorf("AP group not found with name %s", name)
+	return diag.Errorf("AP group not found with name %s", name)
 }
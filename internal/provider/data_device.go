@@ -0,0 +1,411 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataDevice() *schema.Resource {
+	s := dataDeviceSchema()
+	s["site"] = &schema.Schema{
+		Description: "The name of the site the device is associated with.",
+		Type:        schema.TypeString,
+		Computed:    true,
+		Optional:    true,
+	}
+	s["mac"] = &schema.Schema{
+		Description:      "The MAC address of the device to look up.",
+		Type:             schema.TypeString,
+		Computed:         true,
+		Optional:         true,
+		ExactlyOneOf:     []string{"mac", "name"},
+		DiffSuppressFunc: macDiffSuppressFunc,
+		ValidateFunc:     validation.StringMatch(macAddressRegexp, "Mac address is invalid"),
+	}
+	s["name"] = &schema.Schema{
+		Description: "The name of the device to look up.",
+		Type:        schema.TypeString,
+		Computed:    true,
+		Optional:    true,
+	}
+
+	return &schema.Resource{
+		Description: "`unifi_device` data source can be used to retrieve the status of a device by MAC address or " +
+			"name, without managing it.",
+
+		ReadContext: dataDeviceRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultTimeout),
+		},
+
+		Schema: s,
+	}
+}
+
+// dataDeviceSchema returns the attributes exported for a device.
+func dataDeviceSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"id": {
+			Description: "The ID of the device.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"site": {
+			Description: "The name of the site the device is associated with.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"mac": {
+			Description: "The MAC address of the device.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"name": {
+			Description: "The name of the device.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"type": {
+			Description: "The type of the device, for example `uap`, `usw` or `ugw`.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"model": {
+			Description: "The model code of the device.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"firmware_version": {
+			Description: "The firmware version of the device.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"ip": {
+			Description: "The IP address of the device.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"adopted": {
+			Description: "Whether the device is adopted by the controller.",
+			Type:        schema.TypeBool,
+			Computed:    true,
+		},
+		"disabled": {
+			Description: "Whether the device is disabled.",
+			Type:        schema.TypeBool,
+			Computed:    true,
+		},
+		"state": {
+			Description: "The state of the device, for example `connected`, `disconnected` or `pending_adoption`.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"uplink": {
+			Description: "The uplink of the device.",
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"type": {
+						Description: "The type of the uplink, for example `wire` or `wireless`.",
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+					"mac": {
+						Description: "The MAC address of the upstream device.",
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+					"remote_port": {
+						Description: "The port number on the upstream device.",
+						Type:        schema.TypeInt,
+						Computed:    true,
+					},
+					"port_number": {
+						Description: "The local port number of the uplink.",
+						Type:        schema.TypeInt,
+						Computed:    true,
+					},
+					"speed": {
+						Description: "The speed of the uplink in Mbps.",
+						Type:        schema.TypeInt,
+						Computed:    true,
+					},
+					"full_duplex": {
+						Description: "Whether the uplink is full duplex.",
+						Type:        schema.TypeBool,
+						Computed:    true,
+					},
+				},
+			},
+		},
+		"port_table": {
+			Description: "The ports of the device.",
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"number": {
+						Description: "The number of the port.",
+						Type:        schema.TypeInt,
+						Computed:    true,
+					},
+					"name": {
+						Description: "The name of the port.",
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+					"media": {
+						Description: "The media of the port, for example `GE` or `SFP+`.",
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+					"enabled": {
+						Description: "Whether the port is enabled.",
+						Type:        schema.TypeBool,
+						Computed:    true,
+					},
+					"up": {
+						Description: "Whether the port has a link.",
+						Type:        schema.TypeBool,
+						Computed:    true,
+					},
+					"speed": {
+						Description: "The link speed of the port in Mbps.",
+						Type:        schema.TypeInt,
+						Computed:    true,
+					},
+					"full_duplex": {
+						Description: "Whether the link is full duplex.",
+						Type:        schema.TypeBool,
+						Computed:    true,
+					},
+					"is_uplink": {
+						Description: "Whether this port is the uplink of the device.",
+						Type:        schema.TypeBool,
+						Computed:    true,
+					},
+					"port_profile_id": {
+						Description: "The ID of the port profile currently applied to the port.",
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+					"op_mode": {
+						Description: "The operating mode of the port, for example `switch`, `mirror` or `aggregate`.",
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+					"poe_mode": {
+						Description: "The PoE mode of the port.",
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataDeviceAttributes(dev *deviceStatus, site string) map[string]interface{} {
+	ports := make([]interface{}, 0, len(dev.PortTable))
+	for _, p := range dev.PortTable {
+		ports = append(ports, map[string]interface{}{
+			"number":          p.PortIDX,
+			"name":            p.Name,
+			"media":           p.Media,
+			"enabled":         p.Enable,
+			"up":              p.Up,
+			"speed":           p.Speed,
+			"full_duplex":     p.FullDuplex,
+			"is_uplink":       p.IsUplink,
+			"port_profile_id": p.PortProfileID,
+			"op_mode":         p.OpMode,
+			"poe_mode":        p.PoeMode,
+		})
+	}
+
+	uplink := []interface{}{}
+	if dev.Uplink.Type != "" {
+		uplink = append(uplink, map[string]interface{}{
+			"type":        dev.Uplink.Type,
+			"mac":         dev.Uplink.MAC,
+			"remote_port": dev.Uplink.RemotePort,
+			"port_number": dev.Uplink.PortIDX,
+			"speed":       dev.Uplink.Speed,
+			"full_duplex": dev.Uplink.FullDuplex,
+		})
+	}
+
+	return map[string]interface{}{
+		"id":               dev.ID,
+		"site":             site,
+		"mac":              dev.MAC,
+		"name":             dev.Name,
+		"type":             dev.Type,
+		"model":            dev.Model,
+		"firmware_version": dev.Version,
+		"ip":               dev.IP,
+		"adopted":          dev.Adopted,
+		"disabled":         dev.Disabled,
+		"state":            deviceStateName(dev.State),
+		"uplink":           uplink,
+		"port_table":       ports,
+	}
+}
+
+func dataDeviceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client)
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+
+	mac := cleanMAC(d.Get("mac").(string))
+	name := d.Get("name").(string)
+
+	devices, err := c.c.ListDeviceStatus(ctx, site)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var found *deviceStatus
+	for i := range devices {
+		dev := &devices[i]
+		if (mac != "" && cleanMAC(dev.MAC) == mac) || (name != "" && dev.Name == name) {
+			if found != nil {
+				return diag.Errorf("found multiple devices with name %q", name)
+			}
+			found = dev
+		}
+	}
+	if found == nil {
+		if mac != "" {
+			return diag.Errorf("device not found using mac %q", mac)
+		}
+		return diag.Errorf("device not found with name %q", name)
+	}
+
+	d.SetId(found.ID)
+	for k, v := range dataDeviceAttributes(found, site) {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return nil
+}
+
+func dataDevices() *schema.Resource {
+	states := make([]string, 0, len(deviceStateNames))
+	for _, name := range deviceStateNames {
+		states = append(states, name)
+	}
+	sort.Strings(states)
+
+	return &schema.Resource{
+		Description: "`unifi_devices` data source can be used to retrieve the status of all devices of a site, " +
+			"optionally filtered by type, model or state.",
+
+		ReadContext: dataDevicesRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Description: "The ID of this data source.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"site": {
+				Description: "The name of the site to retrieve the devices from.",
+				Type:        schema.TypeString,
+				Computed:    true,
+				Optional:    true,
+			},
+			"type": {
+				Description:  "Only return devices of this type. Must be one of `uap`, `usw`, `ugw`, `udm` or `uxg`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"uap", "usw", "ugw", "udm", "uxg"}, false),
+			},
+			"model": {
+				Description: "Only return devices with this model code.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"state": {
+				Description:  fmt.Sprintf("Only return devices in this state. Must be one of `%s`.", strings.Join(states, "`, `")),
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(states, false),
+			},
+			"ids": {
+				Description: "The IDs of the matching devices.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"devices": {
+				Description: "The matching devices, with the same attributes as the `unifi_device` data source.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: dataDeviceSchema(),
+				},
+			},
+		},
+	}
+}
+
+func dataDevicesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client)
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+
+	devType := d.Get("type").(string)
+	model := d.Get("model").(string)
+	state := d.Get("state").(string)
+
+	devices, err := c.c.ListDeviceStatus(ctx, site)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ids := []string{}
+	list := []interface{}{}
+	for i := range devices {
+		dev := &devices[i]
+		if devType != "" && dev.Type != devType {
+			continue
+		}
+		if model != "" && dev.Model != model {
+			continue
+		}
+		if state != "" && deviceStateName(dev.State) != state {
+			continue
+		}
+
+		ids = append(ids, dev.ID)
+		list = append(list, dataDeviceAttributes(dev, site))
+	}
+
+	d.SetId(site)
+	d.Set("site", site)
+	d.Set("ids", ids)
+	d.Set("devices", list)
+
+	return nil
+}
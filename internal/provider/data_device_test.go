@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataDevices_switches(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { preCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataDevicesConfig_switches,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.unifi_devices.switches", "ids.#"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataDevice_fake(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	for _, dev := range []map[string]interface{}{
+		{
+			"mac": "00:27:22:00:00:01", "name": "Core", "type": "usw", "model": "US24P250", "version": "5.43.23.12533",
+			"ip": "192.168.1.2", "adopted": true, "state": 1,
+			"uplink": map[string]interface{}{"type": "wire", "uplink_mac": "00:27:22:00:00:03", "uplink_remote_port": 2, "port_idx": 24, "speed": 1000, "full_duplex": true},
+			"port_table": []interface{}{
+				map[string]interface{}{"port_idx": 1, "name": "Port 1", "media": "GE", "enable": true, "up": true, "speed": 1000, "portconf_id": "profile1", "op_mode": "switch", "poe_mode": "auto"},
+				map[string]interface{}{"port_idx": 24, "name": "Port 24", "media": "GE", "enable": true, "up": true, "speed": 1000, "is_uplink": true, "op_mode": "switch"},
+			},
+		},
+		{"mac": "00:27:22:00:00:02", "name": "Office", "type": "uap", "model": "U7PG2", "adopted": true, "state": 1},
+		{"mac": "00:27:22:00:00:04", "name": "Office", "type": "uap", "model": "U7PG2", "state": 2},
+	} {
+		if _, err := s.AddObject("default", "device", dev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	state, diags := testFakeDataSource(t, c, "unifi_device", map[string]interface{}{"mac": "00-27-22-00-00-01"})
+	if diags.HasError() {
+		t.Fatal(diagsString(diags))
+	}
+	fakeCheckAttr("name", "Core")(t, state)
+	fakeCheckAttr("model", "US24P250")(t, state)
+	fakeCheckAttr("firmware_version", "5.43.23.12533")(t, state)
+	fakeCheckAttr("ip", "192.168.1.2")(t, state)
+	fakeCheckAttr("state", "connected")(t, state)
+	fakeCheckAttr("uplink.0.mac", "00:27:22:00:00:03")(t, state)
+	fakeCheckAttr("uplink.0.port_number", "24")(t, state)
+	fakeCheckAttr("port_table.#", "2")(t, state)
+	fakeCheckAttr("port_table.0.port_profile_id", "profile1")(t, state)
+	fakeCheckAttr("port_table.1.is_uplink", "true")(t, state)
+
+	state, diags = testFakeDataSource(t, c, "unifi_device", map[string]interface{}{"name": "Core"})
+	if diags.HasError() {
+		t.Fatal(diagsString(diags))
+	}
+	fakeCheckAttr("mac", "00:27:22:00:00:01")(t, state)
+
+	_, diags = testFakeDataSource(t, c, "unifi_device", map[string]interface{}{"name": "Office"})
+	if !diags.HasError() || !strings.Contains(diagsString(diags), "found multiple devices") {
+		t.Fatalf("expected an ambiguous match, got %q", diagsString(diags))
+	}
+
+	_, diags = testFakeDataSource(t, c, "unifi_device", map[string]interface{}{"mac": "00:27:22:00:00:99"})
+	if !diags.HasError() || !strings.Contains(diagsString(diags), "device not found") {
+		t.Fatalf("expected no match, got %q", diagsString(diags))
+	}
+}
+
+func TestDataDevices_fake(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	for _, dev := range []map[string]interface{}{
+		{"mac": "00:27:22:00:00:01", "name": "Core", "type": "usw", "model": "US24P250", "adopted": true, "state": 1},
+		{"mac": "00:27:22:00:00:02", "name": "Office", "type": "uap", "model": "U7PG2", "adopted": true, "state": 1},
+		{"mac": "00:27:22:00:00:04", "type": "uap", "model": "U7NHD", "state": 2},
+	} {
+		if _, err := s.AddObject("default", "device", dev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	state, diags := testFakeDataSource(t, c, "unifi_devices", map[string]interface{}{})
+	if diags.HasError() {
+		t.Fatal(diagsString(diags))
+	}
+	fakeCheckAttr("ids.#", "3")(t, state)
+
+	state, diags = testFakeDataSource(t, c, "unifi_devices", map[string]interface{}{"type": "uap"})
+	if diags.HasError() {
+		t.Fatal(diagsString(diags))
+	}
+	fakeCheckAttr("devices.#", "2")(t, state)
+
+	state, diags = testFakeDataSource(t, c, "unifi_devices", map[string]interface{}{"state": "pending_adoption"})
+	if diags.HasError() {
+		t.Fatal(diagsString(diags))
+	}
+	fakeCheckAttr("devices.#", "1")(t, state)
+	fakeCheckAttr("devices.0.mac", "00:27:22:00:00:04")(t, state)
+	fakeCheckAttr("devices.0.adopted", "false")(t, state)
+
+	state, diags = testFakeDataSource(t, c, "unifi_devices", map[string]interface{}{"type": "uap", "model": "U7PG2"})
+	if diags.HasError() {
+		t.Fatal(diagsString(diags))
+	}
+	fakeCheckAttr("devices.#", "1")(t, state)
+	fakeCheckAttr("devices.0.name", "Office")(t, state)
+}
+
+const testAccDataDevicesConfig_switches = `
+data "unifi_devices" "switches" {
+	type = "usw"
+}
+`
@@ -0,0 +1,302 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/paultyng/go-unifi/unifi"
+)
+
+func dataNetwork() *schema.Resource {
+	s := dataNetworkSchema()
+	s["site"] = &schema.Schema{
+		Description: "The name of the site the network is associated with.",
+		Type:        schema.TypeString,
+		Computed:    true,
+		Optional:    true,
+	}
+	s["name"] = &schema.Schema{
+		Description:  "The name of the network to look up.",
+		Type:         schema.TypeString,
+		Computed:     true,
+		Optional:     true,
+		AtLeastOneOf: []string{"name", "vlan_id", "purpose"},
+	}
+	s["vlan_id"] = &schema.Schema{
+		Description: "The VLAN ID of the network to look up.",
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Optional:    true,
+	}
+	s["purpose"] = &schema.Schema{
+		Description:  "The purpose of the network to look up. Must be one of `corporate`, `guest`, `wan`, or `vlan-only`.",
+		Type:         schema.TypeString,
+		Computed:     true,
+		Optional:     true,
+		ValidateFunc: validation.StringInSlice([]string{"corporate", "guest", "wan", "vlan-only"}, false),
+	}
+
+	return &schema.Resource{
+		Description: "`unifi_network` data source can be used to retrieve an existing network by name, VLAN ID or " +
+			"purpose. All of the specified arguments must match, and they must match exactly one network.",
+
+		ReadContext: dataNetworkRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultTimeout),
+		},
+
+		Schema: s,
+	}
+}
+
+// dataNetworkSchema returns the attributes exported for a network, which are
+// the same as the unifi_network resource.
+func dataNetworkSchema() map[string]*schema.Schema {
+	s := dataSchemaFromResourceSchema(resourceNetwork().Schema)
+	s["x_wan_password"].Sensitive = true
+	return s
+}
+
+type networkFilter struct {
+	name         string
+	vlan         int
+	purpose      string
+	networkGroup string
+}
+
+func networkFilterFromResourceData(d *schema.ResourceData) networkFilter {
+	f := networkFilter{}
+	if v, ok := d.GetOk("name"); ok {
+		f.name = v.(string)
+	}
+	if v, ok := d.GetOk("vlan_id"); ok {
+		f.vlan = v.(int)
+	}
+	if v, ok := d.GetOk("purpose"); ok {
+		f.purpose = v.(string)
+	}
+	if v, ok := d.GetOk("network_group"); ok {
+		f.networkGroup = v.(string)
+	}
+	return f
+}
+
+func (f networkFilter) String() string {
+	parts := []string{}
+	if f.name != "" {
+		parts = append(parts, fmt.Sprintf("name %q", f.name))
+	}
+	if f.vlan != 0 {
+		parts = append(parts, fmt.Sprintf("vlan_id %d", f.vlan))
+	}
+	if f.purpose != "" {
+		parts = append(parts, fmt.Sprintf("purpose %q", f.purpose))
+	}
+	if f.networkGroup != "" {
+		parts = append(parts, fmt.Sprintf("network_group %q", f.networkGroup))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (f networkFilter) matches(n *unifi.Network) bool {
+	if f.name != "" && n.Name != f.name {
+		return false
+	}
+	if f.vlan != 0 && (!n.VLANEnabled || n.VLAN != f.vlan) {
+		return false
+	}
+	if f.purpose != "" && n.Purpose != f.purpose {
+		return false
+	}
+	if f.networkGroup != "" && n.NetworkGroup != f.networkGroup {
+		return false
+	}
+	return true
+}
+
+func dataNetworkRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client)
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+
+	filter := networkFilterFromResourceData(d)
+
+	networks, err := c.c.ListNetwork(ctx, site)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	matches := []*unifi.Network{}
+	for i := range networks {
+		if filter.matches(&networks[i]) {
+			matches = append(matches, &networks[i])
+		}
+	}
+	if len(matches) == 0 {
+		return diag.Errorf("network not found matching %s", filter)
+	}
+	if len(matches) > 1 {
+		names := make([]string, 0, len(matches))
+		for _, n := range matches {
+			names = append(names, n.Name)
+		}
+		return diag.Errorf("found multiple networks matching %s: %s", filter, strings.Join(names, ", "))
+	}
+
+	d.SetId(matches[0].ID)
+
+	return diag.FromErr(resourceNetworkSetResourceData(matches[0], d, site))
+}
+
+func dataNetworks() *schema.Resource {
+	return &schema.Resource{
+		Description: "`unifi_networks` data source can be used to retrieve all of the networks of a site, optionally " +
+			"filtered by name, VLAN ID, purpose or network group.",
+
+		ReadContext: dataNetworksRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Description: "The ID of this data source.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"site": {
+				Description: "The name of the site to retrieve the networks from.",
+				Type:        schema.TypeString,
+				Computed:    true,
+				Optional:    true,
+			},
+			"name": {
+				Description: "Only return networks with this name.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"vlan_id": {
+				Description: "Only return networks with this VLAN ID.",
+				Type:        schema.TypeInt,
+				Optional:    true,
+			},
+			"purpose": {
+				Description:  "Only return networks with this purpose. Must be one of `corporate`, `guest`, `wan`, or `vlan-only`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"corporate", "guest", "wan", "vlan-only"}, false),
+			},
+			"network_group": {
+				Description: "Only return networks in this network group.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"ids": {
+				Description: "The IDs of the matching networks.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"networks": {
+				Description: "The matching networks, with the same attributes as the `unifi_network` data source " +
+					"except for `x_wan_password`.",
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: dataNetworksElemSchema(),
+				},
+			},
+		},
+	}
+}
+
+// dataNetworksElemSchema returns the attributes of each network in the list.
+// Nested attributes cannot be marked sensitive, so the WAN password is left out.
+func dataNetworksElemSchema() map[string]*schema.Schema {
+	s := dataNetworkSchema()
+	delete(s, "x_wan_password")
+	return s
+}
+
+func dataNetworksRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client)
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+
+	filter := networkFilterFromResourceData(d)
+
+	networks, err := c.c.ListNetwork(ctx, site)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	elem := &schema.Resource{Schema: dataNetworksElemSchema()}
+	ids := []string{}
+	list := []interface{}{}
+	for i := range networks {
+		n := &networks[i]
+		if !filter.matches(n) {
+			continue
+		}
+
+		// reuse the resource's flattening through a standalone ResourceData
+		nd := elem.Data(nil)
+		nd.SetId(n.ID)
+		if err := resourceNetworkSetResourceData(n, nd, site); err != nil {
+			return diag.FromErr(err)
+		}
+		m := map[string]interface{}{}
+		for k := range elem.Schema {
+			m[k] = nd.Get(k)
+		}
+		m["id"] = n.ID
+
+		ids = append(ids, n.ID)
+		list = append(list, m)
+	}
+
+	d.SetId(site)
+	d.Set("site", site)
+	d.Set("ids", ids)
+	d.Set("networks", list)
+
+	return nil
+}
+
+// dataSchemaFromResourceSchema returns a copy of a resource schema with every
+// attribute computed, for data sources exporting the same attributes.
+func dataSchemaFromResourceSchema(rs map[string]*schema.Schema) map[string]*schema.Schema {
+	ds := make(map[string]*schema.Schema, len(rs))
+	for k, v := range rs {
+		ds[k] = dataSchemaFromResourceAttribute(v)
+	}
+	return ds
+}
+
+func dataSchemaFromResourceAttribute(s *schema.Schema) *schema.Schema {
+	ds := &schema.Schema{
+		Description: s.Description,
+		Type:        s.Type,
+		Computed:    true,
+		Sensitive:   s.Sensitive,
+	}
+	switch elem := s.Elem.(type) {
+	case *schema.Schema:
+		ds.Elem = &schema.Schema{Type: elem.Type}
+	case *schema.Resource:
+		ds.Elem = &schema.Resource{Schema: dataSchemaFromResourceSchema(elem.Schema)}
+	}
+	return ds
+}
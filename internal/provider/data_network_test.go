@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataNetwork_default(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { preCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataNetworkConfig_default,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.unifi_network.default", "purpose", "corporate"),
+					resource.TestCheckResourceAttrSet("data.unifi_network.default", "subnet"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataNetworks_purpose(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { preCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataNetworksConfig_purpose,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.unifi_networks.wan", "networks.0.purpose", "wan"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataNetwork_fake(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	for _, n := range []map[string]interface{}{
+		{"name": "Default", "purpose": "corporate", "ip_subnet": "192.168.1.1/24", "networkgroup": "LAN"},
+		{"name": "WAN", "purpose": "wan", "wan_type": "pppoe", "x_wan_password": "secret", "wan_networkgroup": "WAN"},
+		{"name": "IoT", "purpose": "corporate", "vlan_enabled": true, "vlan": 20, "ip_subnet": "10.0.20.1/24", "networkgroup": "LAN"},
+		{"name": "Cameras", "purpose": "vlan-only", "vlan_enabled": true, "vlan": 30, "networkgroup": "LAN"},
+	} {
+		if _, err := s.AddObject("default", "networkconf", n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	state, diags := testFakeDataSource(t, c, "unifi_network", map[string]interface{}{"name": "Default"})
+	if diags.HasError() {
+		t.Fatal(diagsString(diags))
+	}
+	fakeCheckAttr("subnet", "192.168.1.0/24")(t, state)
+	fakeCheckAttr("purpose", "corporate")(t, state)
+
+	state, diags = testFakeDataSource(t, c, "unifi_network", map[string]interface{}{"vlan_id": 20})
+	if diags.HasError() {
+		t.Fatal(diagsString(diags))
+	}
+	fakeCheckAttr("name", "IoT")(t, state)
+
+	state, diags = testFakeDataSource(t, c, "unifi_network", map[string]interface{}{"purpose": "wan"})
+	if diags.HasError() {
+		t.Fatal(diagsString(diags))
+	}
+	fakeCheckAttr("wan_type", "pppoe")(t, state)
+	fakeCheckAttr("x_wan_password", "secret")(t, state)
+
+	_, diags = testFakeDataSource(t, c, "unifi_network", map[string]interface{}{"purpose": "corporate"})
+	if !diags.HasError() || !strings.Contains(diagsString(diags), "found multiple networks") {
+		t.Fatalf("expected an ambiguous match, got %q", diagsString(diags))
+	}
+
+	_, diags = testFakeDataSource(t, c, "unifi_network", map[string]interface{}{"name": "Default", "vlan_id": 20})
+	if !diags.HasError() || !strings.Contains(diagsString(diags), "network not found") {
+		t.Fatalf("expected no match, got %q", diagsString(diags))
+	}
+
+	_, diags = testFakeDataSource(t, c, "unifi_network", map[string]interface{}{})
+	if !diags.HasError() {
+		t.Fatal("expected an error without any arguments")
+	}
+}
+
+func TestDataNetworks_fake(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	for _, n := range []map[string]interface{}{
+		{"name": "Default", "purpose": "corporate", "ip_subnet": "192.168.1.1/24", "networkgroup": "LAN"},
+		{"name": "WAN", "purpose": "wan", "wan_networkgroup": "WAN"},
+		{"name": "IoT", "purpose": "corporate", "vlan_enabled": true, "vlan": 20, "ip_subnet": "10.0.20.1/24", "networkgroup": "LAN"},
+	} {
+		if _, err := s.AddObject("default", "networkconf", n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	state, diags := testFakeDataSource(t, c, "unifi_networks", map[string]interface{}{})
+	if diags.HasError() {
+		t.Fatal(diagsString(diags))
+	}
+	fakeCheckAttr("ids.#", "3")(t, state)
+
+	state, diags = testFakeDataSource(t, c, "unifi_networks", map[string]interface{}{"purpose": "corporate"})
+	if diags.HasError() {
+		t.Fatal(diagsString(diags))
+	}
+	fakeCheckAttr("networks.#", "2")(t, state)
+	fakeCheckAttr("networks.1.name", "IoT")(t, state)
+	fakeCheckAttr("networks.1.vlan_id", "20")(t, state)
+	fakeCheckAttr("networks.1.subnet", "10.0.20.0/24")(t, state)
+
+	state, diags = testFakeDataSource(t, c, "unifi_networks", map[string]interface{}{"name": "missing"})
+	if diags.HasError() {
+		t.Fatal(diagsString(diags))
+	}
+	fakeCheckAttr("networks.#", "0")(t, state)
+}
+
+const testAccDataNetworkConfig_default = `
+data "unifi_network" "default" {
+	name = "Default"
+}
+`
+
+const testAccDataNetworksConfig_purpose = `
+data "unifi_networks" "wan" {
+	purpose = "wan"
+}
+`
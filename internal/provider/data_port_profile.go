@@ -2,8 +2,8 @@
 
 import (
 	"context"
-	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -11,7 +11,10 @@ func dataPortProfile() *schema.Resource {
 	return &schema.Resource{
 		Description: "`unifi_port_profile` data source can be used to retrieve the ID for a port profile by name.",
 
-		Read: dataPortProfileRead,
+		ReadContext: dataPortProfileRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultTimeout),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"id": {
@@ -35,7 +38,7 @@ func dataPortProfile() *schema.Resource {
 	}
 }
 
-func dataPortProfileRead(d *schema.ResourceData, meta interface{}) error {
+func dataPortProfileRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	name := d.Get("name").(string)
@@ -44,9 +47,9 @@ func dataPortProfileRead(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	groups, err := c.c.ListPortProfile(context.TODO(), site)
+	groups, err := c.c.ListPortProfile(ctx, site)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	for _, g := range groups {
 		if g.Name == name {
@@ -58,5 +61,5 @@ func dataPortProfileRead(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
-	return fmt.Errorf("port profile not found with name %s", name)
+	return diag.Errorf("port profile not found with name %s", name)
 }
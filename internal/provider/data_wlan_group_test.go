@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -29,3 +30,29 @@ func TestAccDataWLANGroup_default(t *testing.T) {
 data "unifi_wlan_group" "default" {
 }
 `
+
+func TestDataWLANGroup_fake(t *testing.T) {
+	c, s := newFakeClient(t)
+	s.SetVersion("5.12.35")
+
+	id, err := s.AddObject("default", "wlangroup", map[string]interface{}{"name": "Default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, diags := testFakeDataSource(t, c, "unifi_wlan_group", map[string]interface{}{})
+	if diags.HasError() {
+		t.Fatal(diagsString(diags))
+	}
+	fakeCheckAttr("id", id)(t, state)
+
+	c, s = newFakeClient(t)
+	if _, err := s.AddObject("default", "wlangroup", map[string]interface{}{"name": "Default"}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, diags = testFakeDataSource(t, c, "unifi_wlan_group", map[string]interface{}{})
+	if !diags.HasError() || !strings.Contains(diagsString(diags), "unifi_wlan_group is not supported") {
+		t.Fatalf("expected an unsupported controller version, got %q", diagsString(diags))
+	}
+}
@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// devicePollInterval is the delay between checks of a device's state while
+// waiting for a command to complete.
+var devicePollInterval = 5 * time.Second
+
+// deviceProvisionStartTimeout bounds the wait for a device to start applying a
+// change, a change that does not need provisioning never takes the device out
+// of the connected state.
+var deviceProvisionStartTimeout = time.Minute
+
+// deviceCommand issues a command of the manager, devmgr or sitemgr, for the
+// device with the given MAC.
+func (c *lazyClient) deviceCommand(ctx context.Context, site, mgr, cmd, mac string) error {
+	if err := c.init(ctx); err != nil {
+		return err
+	}
+
+	reqBody := struct {
+		Cmd string `json:"cmd"`
+		MAC string `json:"mac"`
+	}{cmd, mac}
+	return c.doV1(ctx, "POST", fmt.Sprintf("s/%s/cmd/%s", site, mgr), reqBody, nil)
+}
+
+func (c *lazyClient) AdoptDevice(ctx context.Context, site, mac string) error {
+	return c.deviceCommand(ctx, site, "devmgr", "adopt", mac)
+}
+
+// ForgetDevice removes an adopted device from the site, the controller
+// implements this as a site command rather than a device command.
+func (c *lazyClient) ForgetDevice(ctx context.Context, site, mac string) error {
+	return c.deviceCommand(ctx, site, "sitemgr", "delete-device", mac)
+}
+
+// SetDeviceDisabled disables or enables the device. unifi.Device omits the
+// disabled field when it is false, so it cannot enable a device.
+func (c *lazyClient) SetDeviceDisabled(ctx context.Context, site, id string, disabled bool) error {
+	if err := c.init(ctx); err != nil {
+		return err
+	}
+
+	reqBody := struct {
+		Disabled bool `json:"disabled"`
+	}{disabled}
+	return c.doV1(ctx, "PUT", fmt.Sprintf("s/%s/rest/device/%s", site, id), reqBody, nil)
+}
+
+// findDeviceStatus returns the live state of the device with the given MAC,
+// or nil if the controller does not know the device.
+func findDeviceStatus(ctx context.Context, c unifiClient, site, mac string) (*deviceStatus, error) {
+	devices, err := c.ListDeviceStatus(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+
+	mac = cleanMAC(mac)
+	for i := range devices {
+		if cleanMAC(devices[i].MAC) == mac {
+			return &devices[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// waitForDeviceState polls the device until it reaches the state, the context
+// deadline is the operation's timeout.
+func waitForDeviceState(ctx context.Context, c unifiClient, site, mac string, state int) error {
+	_, err := waitForDevice(ctx, c, site, mac, deviceStateName(state), func(dev *deviceStatus) bool { return dev.State == state })
+	return err
+}
+
+// waitForDeviceReconnect waits for a connected device to apply a change, which
+// takes it out of the connected state while it is provisioned, and to come
+// back. cfgVersion is the configuration version of the device before the
+// change, a new version shows the change was provisioned even if the device
+// was never seen provisioning. If the device neither leaves the connected
+// state nor reports a new version in deviceProvisionStartTimeout, the change
+// is assumed to not need provisioning. It returns the configuration version
+// of the reconnected device.
+func waitForDeviceReconnect(ctx context.Context, c unifiClient, site, mac, cfgVersion string) (string, error) {
+	startCtx, cancel := context.WithTimeout(ctx, deviceProvisionStartTimeout)
+	defer cancel()
+
+	_, err := waitForDevice(startCtx, c, site, mac, "provisioning", func(dev *deviceStatus) bool {
+		return dev.State != deviceStateConnected || dev.CfgVersion != cfgVersion
+	})
+	switch {
+	case err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded):
+		log.Printf("[DEBUG] device %q was not provisioned after %s, continuing", mac, deviceProvisionStartTimeout)
+	case err != nil:
+		return "", err
+	}
+
+	dev, err := waitForDevice(ctx, c, site, mac, deviceStateName(deviceStateConnected), func(dev *deviceStatus) bool {
+		return dev.State == deviceStateConnected
+	})
+	if err != nil {
+		return "", err
+	}
+	return dev.CfgVersion, nil
+}
+
+// waitForDevice polls the device until done returns true for it and returns
+// its last status, want describes the awaited state in errors.
+func waitForDevice(ctx context.Context, c unifiClient, site, mac, want string, done func(dev *deviceStatus) bool) (*deviceStatus, error) {
+	for {
+		dev, err := findDeviceStatus(ctx, c, site, mac)
+		if err != nil {
+			return nil, err
+		}
+		if dev == nil {
+			return nil, fmt.Errorf("device not found using mac %q", mac)
+		}
+		if done(dev) {
+			return dev, nil
+		}
+		if dev.State == deviceStateAdoptionFailed {
+			return nil, fmt.Errorf("adoption of device %q failed", mac)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timeout waiting for device %q to be %s, it is %s: %w",
+				mac, want, deviceStateName(dev.State), ctx.Err())
+		case <-time.After(devicePollInterval):
+		}
+	}
+}
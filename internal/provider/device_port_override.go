@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/paultyng/go-unifi/unifi"
+)
+
+// devicePortOverride is a port override of a device. unifi.DevicePortOverrides
+// omits false and zero values, so settings could not be turned off, and does
+// not know the networks of a port, so overrides are read and written with
+// separate requests. Only the settings of the configuration are sent, so the
+// port profile and controller defaults apply to the others. Settings that
+// default to on are pointers, nil meaning the default.
+//
+// The first port of a link aggregation has op_mode aggregate and the number of
+// consecutive member ports, a port mirroring another has op_mode mirror and
+// the index of the mirrored port.
+type devicePortOverride struct {
+	PortIDX       int    `json:"port_idx"`
+	Name          string `json:"name,omitempty"`
+	PortProfileID string `json:"portconf_id,omitempty"`
+	OpMode        string `json:"op_mode,omitempty"`
+	PoeMode       string `json:"poe_mode,omitempty"`
+
+	AggregateNumPorts int `json:"aggregate_num_ports,omitempty"`
+	MirrorPortIDX     int `json:"mirror_port_idx,omitempty"`
+
+	NativeNetworkID  string   `json:"native_networkconf_id,omitempty"`
+	TaggedNetworkIDs []string `json:"tagged_networkconf_ids,omitempty"`
+
+	Autoneg    *bool `json:"autoneg,omitempty"`
+	Speed      int   `json:"speed,omitempty"`
+	FullDuplex bool  `json:"full_duplex,omitempty"`
+
+	StormctrlType          string `json:"stormctrl_type,omitempty"`
+	StormctrlBcastEnabled  bool   `json:"stormctrl_bcast_enabled,omitempty"`
+	StormctrlBcastRate     int    `json:"stormctrl_bcast_rate,omitempty"`
+	StormctrlMcastEnabled  bool   `json:"stormctrl_mcast_enabled,omitempty"`
+	StormctrlMcastRate     int    `json:"stormctrl_mcast_rate,omitempty"`
+	StormctrlUcastEnabled  bool   `json:"stormctrl_ucast_enabled,omitempty"`
+	StormctrlUcastRate     int    `json:"stormctrl_ucast_rate,omitempty"`
+	StpPortMode            *bool  `json:"stp_port_mode,omitempty"`
+	LldpmedEnabled         *bool  `json:"lldpmed_enabled,omitempty"`
+	LldpmedNotifyEnabled   bool   `json:"lldpmed_notify_enabled,omitempty"`
+	Isolation              bool   `json:"isolation,omitempty"`
+	EgressRateLimitEnabled bool   `json:"egress_rate_limit_kbps_enabled,omitempty"`
+	EgressRateLimitKbps    int    `json:"egress_rate_limit_kbps,omitempty"`
+}
+
+func (c *lazyClient) GetDevicePortOverrides(ctx context.Context, site, id string) ([]devicePortOverride, error) {
+	if err := c.init(ctx); err != nil {
+		return nil, err
+	}
+
+	var respBody struct {
+		Data []struct {
+			PortOverrides []devicePortOverride `json:"port_overrides"`
+		} `json:"data"`
+	}
+	err := c.doV1(ctx, "GET", fmt.Sprintf("s/%s/rest/device/%s", site, id), nil, &respBody)
+	if err != nil {
+		return nil, err
+	}
+	if len(respBody.Data) != 1 {
+		return nil, &unifi.NotFoundError{}
+	}
+	return respBody.Data[0].PortOverrides, nil
+}
+
+// UpdateDevicePortOverrides replaces all of the port overrides of the device.
+func (c *lazyClient) UpdateDevicePortOverrides(ctx context.Context, site, id string, overrides []devicePortOverride) ([]devicePortOverride, error) {
+	if err := c.init(ctx); err != nil {
+		return nil, err
+	}
+
+	reqBody := struct {
+		PortOverrides []devicePortOverride `json:"port_overrides"`
+	}{overrides}
+	var respBody struct {
+		Data []struct {
+			PortOverrides []devicePortOverride `json:"port_overrides"`
+		} `json:"data"`
+	}
+	err := c.doV1(ctx, "PUT", fmt.Sprintf("s/%s/rest/device/%s", site, id), reqBody, &respBody)
+	if err != nil {
+		return nil, err
+	}
+	if len(respBody.Data) != 1 {
+		return nil, &unifi.NotFoundError{}
+	}
+	return respBody.Data[0].PortOverrides, nil
+}
+
+// deviceUplinkPort returns the local port of a wired uplink, or 0 if the
+// device is not known to be uplinked through one of its ports.
+func deviceUplinkPort(dev *deviceStatus) int {
+	if dev.Uplink.Type == "wire" && dev.Uplink.PortIDX != 0 {
+		return dev.Uplink.PortIDX
+	}
+	for _, p := range dev.PortTable {
+		if p.IsUplink {
+			return p.PortIDX
+		}
+	}
+	return 0
+}
+
+// uplinkPortGroup returns the uplink port and every port aggregated with it,
+// either currently or after the update, including aggregates overlapping
+// those ports.
+func uplinkPortGroup(uplink int, current, final []devicePortOverride) map[int]bool {
+	type portRange struct{ first, last int }
+	ranges := []portRange{}
+	for _, overrides := range [][]devicePortOverride{current, final} {
+		for _, po := range overrides {
+			if po.OpMode == "aggregate" && po.AggregateNumPorts > 0 {
+				ranges = append(ranges, portRange{po.PortIDX, po.PortIDX + po.AggregateNumPorts - 1})
+			}
+		}
+	}
+
+	group := map[int]bool{uplink: true}
+	for changed := true; changed; {
+		changed = false
+		for _, r := range ranges {
+			overlaps := false
+			for p := r.first; p <= r.last; p++ {
+				overlaps = overlaps || group[p]
+			}
+			if !overlaps {
+				continue
+			}
+			for p := r.first; p <= r.last; p++ {
+				if !group[p] {
+					group[p] = true
+					changed = true
+				}
+			}
+		}
+	}
+	return group
+}
+
+// uplinkInterimPortOverrides returns the overrides to apply before the final
+// ones, which have every change except to the ports of the uplink group. It
+// reports false if the uplink group does not change, in which case the final
+// overrides can be applied at once.
+func uplinkInterimPortOverrides(uplink int, current, final []devicePortOverride) ([]devicePortOverride, bool) {
+	group := uplinkPortGroup(uplink, current, final)
+
+	currentGroup := map[int]devicePortOverride{}
+	interim := []devicePortOverride{}
+	for _, po := range current {
+		if group[po.PortIDX] {
+			currentGroup[po.PortIDX] = po
+			interim = append(interim, po)
+		}
+	}
+
+	finalGroup := map[int]devicePortOverride{}
+	for _, po := range final {
+		if group[po.PortIDX] {
+			finalGroup[po.PortIDX] = po
+			continue
+		}
+		interim = append(interim, po)
+	}
+
+	if reflect.DeepEqual(currentGroup, finalGroup) {
+		return nil, false
+	}
+	sort.Slice(interim, func(i, j int) bool { return interim[i].PortIDX < interim[j].PortIDX })
+	return interim, true
+}
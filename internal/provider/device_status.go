@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+)
+
+// deviceStatus is the state of a device as reported by stat/device, which
+// includes read only fields that unifi.Device does not decode.
+type deviceStatus struct {
+	ID       string `json:"_id"`
+	MAC      string `json:"mac"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Model    string `json:"model"`
+	Version  string `json:"version"`
+	IP       string `json:"ip"`
+	Adopted  bool   `json:"adopted"`
+	Disabled bool   `json:"disabled"`
+	State    int    `json:"state"`
+
+	// CfgVersion changes when the device is provisioned with a new configuration
+	CfgVersion string `json:"cfgversion"`
+
+	Uplink    deviceUplinkStatus `json:"uplink"`
+	PortTable []devicePortStatus `json:"port_table"`
+}
+
+type deviceUplinkStatus struct {
+	Type       string `json:"type"`
+	MAC        string `json:"uplink_mac"`
+	RemotePort int    `json:"uplink_remote_port"`
+	PortIDX    int    `json:"port_idx"`
+	Speed      int    `json:"speed"`
+	FullDuplex bool   `json:"full_duplex"`
+	Up         bool   `json:"up"`
+}
+
+type devicePortStatus struct {
+	PortIDX       int    `json:"port_idx"`
+	Name          string `json:"name"`
+	Media         string `json:"media"`
+	Enable        bool   `json:"enable"`
+	Up            bool   `json:"up"`
+	Speed         int    `json:"speed"`
+	FullDuplex    bool   `json:"full_duplex"`
+	IsUplink      bool   `json:"is_uplink"`
+	PortProfileID string `json:"portconf_id"`
+	OpMode        string `json:"op_mode"`
+	PoeMode       string `json:"poe_mode"`
+}
+
+// These are the values of the state field of a device.
+const (
+	deviceStateDisconnected    = 0
+	deviceStateConnected       = 1
+	deviceStatePendingAdoption = 2
+	deviceStateUpgrading       = 4
+	deviceStateProvisioning    = 5
+	deviceStateHeartbeatMissed = 6
+	deviceStateAdopting        = 7
+	deviceStateAdoptionFailed  = 9
+	deviceStateIsolated        = 11
+)
+
+var deviceStateNames = map[int]string{
+	deviceStateDisconnected:    "disconnected",
+	deviceStateConnected:       "connected",
+	deviceStatePendingAdoption: "pending_adoption",
+	deviceStateUpgrading:       "upgrading",
+	deviceStateProvisioning:    "provisioning",
+	deviceStateHeartbeatMissed: "heartbeat_missed",
+	deviceStateAdopting:        "adopting",
+	deviceStateAdoptionFailed:  "adoption_failed",
+	deviceStateIsolated:        "isolated",
+}
+
+func deviceStateName(state int) string {
+	if name, ok := deviceStateNames[state]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ListDeviceStatus is not cached as it reports the live state of devices.
+func (c *lazyClient) ListDeviceStatus(ctx context.Context, site string) ([]deviceStatus, error) {
+	if err := c.init(ctx); err != nil {
+		return nil, err
+	}
+
+	var respBody struct {
+		Data []deviceStatus `json:"data"`
+	}
+	err := c.doV1(ctx, "GET", fmt.Sprintf("s/%s/stat/device", site), nil, &respBody)
+	if err != nil {
+		return nil, err
+	}
+	return respBody.Data, nil
+}
@@ -0,0 +1,301 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/paultyng/terraform-provider-unifi/internal/fakeunifi"
+)
+
+// The tests using these helpers run the resources against an in-process fake
+// controller, so they do not require TF_ACC, a controller or the Terraform CLI.
+// They drive the resources through the same plan, apply and refresh calls that
+// Terraform uses, but only with flat configurations (no references).
+
+// newFakeClient returns a provider client for a new fake controller.
+func newFakeClient(t *testing.T) (*client, *fakeunifi.Server) {
+	t.Helper()
+
+	s := fakeunifi.NewServer()
+	t.Cleanup(s.Close)
+
+	return &client{
+		c: newCachingClient(&lazyClient{
+			baseURL: s.URL,
+			user:    fakeunifi.DefaultUsername,
+			pass:    fakeunifi.DefaultPassword,
+		}, defaultListCacheTTL),
+		site: "default",
+	}, s
+}
+
+type fakeTestCase struct {
+	// resource is the type of the resource under test
+	resource string
+
+	steps []fakeStep
+
+	// skipCheckDestroy skips verifying the resource is gone after destroy, for
+	// resources that are only removed from state
+	skipCheckDestroy bool
+}
+
+type fakeStep struct {
+	// config is the raw configuration of the resource for the step
+	config map[string]interface{}
+
+	// check is called with the state after applying and refreshing
+	check func(t *testing.T, state *terraform.InstanceState)
+
+	// importStateVerify imports the resource by its ID after the step and
+	// compares the state, ignoring the importStateVerifyIgnore attributes
+	importStateVerify       bool
+	importStateVerifyIgnore []string
+	// importStateID is imported instead of the ID of the resource when set
+	importStateID string
+
+	// expectError is part of the error the validation or apply must fail
+	// with. The state left by the failed step is checked and kept for the
+	// next step.
+	expectError string
+}
+
+// testFakeResource validates and applies each configuration in turn,
+// verifying the plan is empty after every step, and finally destroys the
+// resource.
+func testFakeResource(t *testing.T, c *client, tc fakeTestCase) {
+	t.Helper()
+
+	ctx := context.Background()
+	r := New("test")().ResourcesMap[tc.resource]
+	if r == nil {
+		t.Fatalf("resource %q not found", tc.resource)
+	}
+
+	var state *terraform.InstanceState
+	for i, step := range tc.steps {
+		cfg := terraform.NewResourceConfigRaw(step.config)
+
+		if diags := r.Validate(cfg); diags.HasError() {
+			if step.expectError == "" || !strings.Contains(diagsString(diags), step.expectError) {
+				t.Fatalf("step %d: validate: %s", i, diagsString(diags))
+			}
+			if step.check != nil {
+				step.check(t, state)
+			}
+			continue
+		}
+
+		// like Terraform, refresh before planning
+		if state != nil {
+			state = testFakeRefresh(t, r, state, c)
+		}
+
+		diff, err := r.Diff(ctx, state, cfg, c)
+		if err != nil {
+			t.Fatalf("step %d: plan: %s", i, err)
+		}
+		if diff != nil {
+			var diags diag.Diagnostics
+			state, diags = r.Apply(ctx, state, diff, c)
+			if step.expectError != "" {
+				if !strings.Contains(diagsString(diags), step.expectError) {
+					t.Fatalf("step %d: expected apply to fail with %q, got %q", i, step.expectError, diagsString(diags))
+				}
+				if step.check != nil {
+					step.check(t, state)
+				}
+				continue
+			}
+			if diags.HasError() {
+				t.Fatalf("step %d: apply: %s", i, diagsString(diags))
+			}
+		}
+
+		state = testFakeRefresh(t, r, state, c)
+		if state == nil {
+			t.Fatalf("step %d: resource removed from state on refresh", i)
+		}
+
+		diff, err = r.Diff(ctx, state, cfg, c)
+		if err != nil {
+			t.Fatalf("step %d: plan after apply: %s", i, err)
+		}
+		if diff != nil && !diff.Empty() {
+			t.Fatalf("step %d: expected an empty plan after apply, got changes to %s", i, diffAttributes(diff))
+		}
+
+		if step.check != nil {
+			step.check(t, state)
+		}
+
+		if step.importStateVerify {
+			testFakeImport(t, r, state, c, step.importStateID, step.importStateVerifyIgnore)
+		}
+	}
+
+	if state == nil {
+		return
+	}
+
+	_, diags := r.Apply(ctx, state, &terraform.InstanceDiff{Destroy: true}, c)
+	if diags.HasError() {
+		t.Fatalf("destroy: %s", diagsString(diags))
+	}
+	if tc.skipCheckDestroy {
+		return
+	}
+	if refreshed := testFakeRefresh(t, r, state, c); refreshed != nil {
+		t.Fatalf("expected resource %q to be destroyed", state.ID)
+	}
+}
+
+func testFakeRefresh(t *testing.T, r *schema.Resource, state *terraform.InstanceState, c *client) *terraform.InstanceState {
+	t.Helper()
+
+	state, diags := r.RefreshWithoutUpgrade(context.Background(), state, c)
+	if diags.HasError() {
+		t.Fatalf("refresh: %s", diagsString(diags))
+	}
+	return state
+}
+
+func testFakeImport(t *testing.T, r *schema.Resource, state *terraform.InstanceState, c *client, importID string, ignore []string) {
+	t.Helper()
+
+	if r.Importer == nil {
+		t.Fatal("resource does not support import")
+	}
+
+	if importID == "" {
+		importID = state.ID
+	}
+	d := r.Data(&terraform.InstanceState{ID: importID})
+	var (
+		imported []*schema.ResourceData
+		err      error
+	)
+	if r.Importer.StateContext != nil {
+		imported, err = r.Importer.StateContext(context.Background(), d, c)
+	} else {
+		imported, err = r.Importer.State(d, c)
+	}
+	if err != nil {
+		t.Fatalf("import: %s", err)
+	}
+	if len(imported) != 1 {
+		t.Fatalf("import: expected 1 resource, got %d", len(imported))
+	}
+
+	importedState := testFakeRefresh(t, r, imported[0].State(), c)
+	if importedState == nil {
+		t.Fatal("import: resource not found")
+	}
+
+	skip := func(k string) bool {
+		for _, prefix := range ignore {
+			if strings.HasPrefix(k, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	for k, v := range state.Attributes {
+		if skip(k) {
+			continue
+		}
+		if actual := importedState.Attributes[k]; actual != v {
+			t.Errorf("import: attribute %q: expected %q, got %q", k, v, actual)
+		}
+	}
+	for k, v := range importedState.Attributes {
+		if _, ok := state.Attributes[k]; !ok && !skip(k) {
+			t.Errorf("import: unexpected attribute %q = %q", k, v)
+		}
+	}
+}
+
+func diagsString(diags diag.Diagnostics) string {
+	msgs := make([]string, 0, len(diags))
+	for _, d := range diags {
+		msg := d.Summary
+		if d.Detail != "" {
+			msg += ": " + d.Detail
+		}
+		msgs = append(msgs, msg)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func diffAttributes(diff *terraform.InstanceDiff) string {
+	attrs := make([]string, 0, len(diff.Attributes))
+	for k := range diff.Attributes {
+		attrs = append(attrs, k)
+	}
+	sort.Strings(attrs)
+	if diff.RequiresNew() {
+		attrs = append(attrs, "(replacement)")
+	}
+	return strings.Join(attrs, ", ")
+}
+
+func fakeCheckAttr(key, expected string) func(*testing.T, *terraform.InstanceState) {
+	return func(t *testing.T, state *terraform.InstanceState) {
+		t.Helper()
+		if actual := state.Attributes[key]; actual != expected {
+			t.Fatalf("attribute %q: expected %q, got %q", key, expected, actual)
+		}
+	}
+}
+
+// testFakePlanError plans creating the resource and returns the error, the
+// test fails if the plan succeeds.
+func testFakePlanError(t *testing.T, c *client, resource string, config map[string]interface{}) error {
+	t.Helper()
+
+	r := New("test")().ResourcesMap[resource]
+	if r == nil {
+		t.Fatalf("resource %q not found", resource)
+	}
+
+	cfg := terraform.NewResourceConfigRaw(config)
+	if diags := r.Validate(cfg); diags.HasError() {
+		return errors.New(diagsString(diags))
+	}
+
+	_, err := r.Diff(context.Background(), nil, cfg, c)
+	if err == nil {
+		t.Fatal("expected the plan to fail")
+	}
+	return err
+}
+
+// testFakeDataSource validates and reads the data source with the
+// configuration, returning the resulting state.
+func testFakeDataSource(t *testing.T, c *client, dataSource string, config map[string]interface{}) (*terraform.InstanceState, diag.Diagnostics) {
+	t.Helper()
+
+	ctx := context.Background()
+	r := New("test")().DataSourcesMap[dataSource]
+	if r == nil {
+		t.Fatalf("data source %q not found", dataSource)
+	}
+
+	cfg := terraform.NewResourceConfigRaw(config)
+	if diags := r.Validate(cfg); diags.HasError() {
+		return nil, diags
+	}
+
+	diff, err := r.Diff(ctx, nil, cfg, c)
+	if err != nil {
+		t.Fatalf("plan: %s", err)
+	}
+	return r.ReadDataApply(ctx, diff, c)
+}
@@ -1,12 +1,14 @@
 package provider
 
 import (
+	"context"
+	"fmt"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-func importSiteAndID(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+func importSiteAndID(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	if id := d.Id(); strings.Contains(id, ":") {
 		importParts := strings.SplitN(id, ":", 2)
 		d.SetId(importParts[1])
@@ -14,3 +16,68 @@ func importSiteAndID(d *schema.ResourceData, meta interface{}) ([]*schema.Resour
 	}
 	return []*schema.ResourceData{d}, nil
 }
+
+// importName is the ID and name of an object that can be imported by name.
+type importName struct {
+	id   string
+	name string
+}
+
+// importNamesFunc lists the objects of a site that can be imported by name.
+type importNamesFunc func(ctx context.Context, c unifiClient, site string) ([]importName, error)
+
+// importSiteAndName returns an importer accepting the same IDs as
+// importSiteAndID, where the ID can also be name=<name> to look the object up
+// by name, for example default:name=Guest.
+func importSiteAndName(kind string, list importNamesFunc) schema.StateContextFunc {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+		c := meta.(*client)
+		id := d.Id()
+
+		site := ""
+		if strings.Contains(id, ":") {
+			importParts := strings.SplitN(id, ":", 2)
+			site = importParts[0]
+			id = importParts[1]
+		}
+
+		if strings.HasPrefix(id, "name=") {
+			lookupSite := site
+			if lookupSite == "" {
+				lookupSite = c.site
+			}
+
+			names, err := list(ctx, c.c, lookupSite)
+			if err != nil {
+				return nil, err
+			}
+			if id, err = findIDByName(kind, strings.TrimPrefix(id, "name="), names); err != nil {
+				return nil, err
+			}
+		}
+
+		d.SetId(id)
+		if site != "" {
+			d.Set("site", site)
+		}
+		return []*schema.ResourceData{d}, nil
+	}
+}
+
+func findIDByName(kind, name string, names []importName) (string, error) {
+	ids := []string{}
+	for _, n := range names {
+		if n.name == name {
+			ids = append(ids, n.id)
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("%s not found with name %q", kind, name)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("%s name %q is ambiguous, it matches IDs %s", kind, name, strings.Join(ids, ", "))
+	}
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/paultyng/go-unifi/unifi"
+)
+
+func TestImportSiteAndName_fake(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	guestID, err := s.AddObject("default", "usergroup", unifi.UserGroup{Name: "Guest"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Shared", "Shared"} {
+		if _, err := s.AddObject("default", "usergroup", unifi.UserGroup{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := resourceUserGroup()
+	for _, tc := range []struct {
+		id           string
+		expectedID   string
+		expectedSite string
+		expectedErr  string
+	}{
+		{id: guestID, expectedID: guestID},
+		{id: "default:" + guestID, expectedID: guestID, expectedSite: "default"},
+		{id: "name=Guest", expectedID: guestID},
+		{id: "default:name=Guest", expectedID: guestID, expectedSite: "default"},
+		{id: "name=Missing", expectedErr: `user group not found with name "Missing"`},
+		{id: "name=Shared", expectedErr: `user group name "Shared" is ambiguous`},
+	} {
+		t.Run(tc.id, func(t *testing.T) {
+			d := r.TestResourceData()
+			d.SetId(tc.id)
+
+			imported, err := r.Importer.StateContext(context.Background(), d, c)
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := imported[0].Id(); actual != tc.expectedID {
+				t.Fatalf("expected ID %q, got %q", tc.expectedID, actual)
+			}
+			if actual := imported[0].Get("site").(string); actual != tc.expectedSite {
+				t.Fatalf("expected site %q, got %q", tc.expectedSite, actual)
+			}
+		})
+	}
+}
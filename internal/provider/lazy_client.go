@@ -2,7 +2,7 @@
 
 import (
 	"context"
-	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log"
 	"net"
@@ -11,21 +11,45 @@
 	"sync"
 	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/logging"
+	"github.com/hashicorp/go-version"
 	"github.com/paultyng/go-unifi/unifi"
 )
 
 type lazyClient struct {
-	baseURL  string
-	user     string
-	pass     string
-	insecure bool
+	baseURL string
+	user    string
+	pass    string
 
-	once  sync.Once
-	inner *unifi.Client
+	httpConfig httpClientConfig
+
+	once    sync.Once
+	initErr error
+	inner   *unifi.Client
+	version *version.Version
+
+	// hc and apiV2Path are used for raw requests to endpoints not yet
+	// supported by the SDK, see lazy_client_do.go
+	hc        *http.Client
+	apiV2Path string
 }
 
-func setHTTPClient(c *unifi.Client, insecure bool) {
+type httpClientConfig struct {
+	insecure    bool
+	rootCAs     *x509.CertPool
+	fingerprint []byte
+	apiKey      string
+
+	maxRetries   int
+	retryWaitMin time.Duration
+	retryWaitMax time.Duration
+
+	maxRequestsPerSecond  float64
+	maxConcurrentRequests int
+}
+
+func setHTTPClient(c *unifi.Client, config httpClientConfig) *http.Client {
+	jar, _ := cookiejar.New(nil)
+
 	httpClient := &http.Client{}
 	httpClient.Transport = &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
@@ -39,43 +63,82 @@ func setHTTPClient(c *unifi.Client, insecure bool) {
 		TLSHandshakeTimeout:   10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
 
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: insecure,
-		},
+		TLSClientConfig: newTLSConfig(config),
+	}
+
+	// retries and logins are limited as well, so this wraps the base transport
+	if config.maxRequestsPerSecond > 0 || config.maxConcurrentRequests > 0 {
+		httpClient.Transport = newRateLimitTransport(httpClient.Transport, config.maxRequestsPerSecond, config.maxConcurrentRequests)
 	}
 
-	httpClient.Transport = logging.NewTransport("Unifi", httpClient.Transport)
+	// this is installed even when retries are disabled, to log in again when
+	// the session expires
+	httpClient.Transport = &retryTransport{
+		next:       httpClient.Transport,
+		jar:        jar,
+		maxRetries: config.maxRetries,
+		waitMin:    config.retryWaitMin,
+		waitMax:    config.retryWaitMax,
+	}
+
+	if config.apiKey != "" {
+		httpClient.Transport = &apiKeyTransport{
+			apiKey: config.apiKey,
+			next:   httpClient.Transport,
+		}
+	}
+
+	httpClient.Transport = newLoggingTransport("Unifi", httpClient.Transport)
 
-	jar, _ := cookiejar.New(nil)
 	httpClient.Jar = jar
 
 	c.SetHTTPClient(httpClient)
-}
 
-var initErr error
+	return httpClient
+}
 
 func (c *lazyClient) init(ctx context.Context) error {
 	c.once.Do(func() {
-		c.inner = &unifi.Client{}
-		setHTTPClient(c.inner, c.insecure)
+		c.initErr = c.login(ctx)
+	})
+	return c.initErr
+}
 
-		initErr = c.inner.SetBaseURL(c.baseURL)
-		if initErr != nil {
-			return
-		}
+func (c *lazyClient) login(ctx context.Context) error {
+	c.inner = &unifi.Client{}
+	c.hc = setHTTPClient(c.inner, c.httpConfig)
 
-		initErr = c.inner.Login(ctx, c.user, c.pass)
+	err := c.inner.SetBaseURL(c.baseURL)
+	if err != nil {
+		return &controllerError{kind: controllerErrorBaseURL, err: err}
+	}
 
-		log.Printf("[TRACE] Unifi controller version: %q", c.inner.Version())
-	})
-	return initErr
+	// when using an API key, the login request is never sent to the controller
+	err = c.inner.Login(ctx, c.user, c.pass)
+	if err != nil {
+		return classifyLoginError(err)
+	}
+
+	log.Printf("[TRACE] Unifi controller version: %q", c.inner.Version())
+
+	c.version, err = version.NewVersion(c.inner.Version())
+	if err != nil {
+		return &controllerError{kind: controllerErrorVersion, err: err}
+	}
+
+	c.apiV2Path, err = c.discoverAPIV2Path(ctx)
+	if err != nil {
+		return classifyLoginError(err)
+	}
+
+	return nil
 }
 
-func (c *lazyClient) Version() string {
-	if err := c.init(context.Background()); err != nil {
-		panic(fmt.Sprintf("client not initialized: %s", err))
+func (c *lazyClient) Version(ctx context.Context) (*version.Version, error) {
+	if err := c.init(ctx); err != nil {
+		return nil, err
 	}
-	return c.inner.Version()
+	return c.version, nil
 }
 func (c *lazyClient) ListUserGroup(ctx context.Context, site string) ([]unifi.UserGroup, error) {
 	if err := c.init(ctx); err != nil {
@@ -95,6 +158,45 @@ func (c *lazyClient) ListAPGroup(ctx context.Context, site string) ([]unifi.APGr
 	}
 	return c.inner.ListAPGroup(ctx, site)
 }
+func (c *lazyClient) GetAPGroup(ctx context.Context, site, id string) (*unifi.APGroup, error) {
+	if err := c.init(ctx); err != nil {
+		return nil, err
+	}
+	// there is no endpoint to retrieve a single AP group
+	groups, err := c.inner.ListAPGroup(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		if g.ID == id {
+			return &g, nil
+		}
+	}
+	return nil, &unifi.NotFoundError{}
+}
+func (c *lazyClient) CreateAPGroup(ctx context.Context, site string, d *unifi.APGroup) (*unifi.APGroup, error) {
+	if err := c.init(ctx); err != nil {
+		return nil, err
+	}
+	return c.inner.CreateAPGroup(ctx, site, d)
+}
+func (c *lazyClient) UpdateAPGroup(ctx context.Context, site string, d *unifi.APGroup) (*unifi.APGroup, error) {
+	if err := c.init(ctx); err != nil {
+		return nil, err
+	}
+	var resp unifi.APGroup
+	err := c.doV2(ctx, "PUT", fmt.Sprintf("site/%s/apgroups/%s", site, d.ID), d, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+func (c *lazyClient) DeleteAPGroup(ctx context.Context, site, id string) error {
+	if err := c.init(ctx); err != nil {
+		return err
+	}
+	return c.doV2(ctx, "DELETE", fmt.Sprintf("site/%s/apgroups/%s", site, id), nil, nil)
+}
 func (c *lazyClient) DeleteNetwork(ctx context.Context, site, id, name string) error {
 	if err := c.init(ctx); err != nil {
 		return err
@@ -125,17 +227,17 @@ func (c *lazyClient) UpdateNetwork(ctx context.Context, site string, d *unifi.Ne
 	}
 	return c.inner.UpdateNetwork(ctx, site, d)
 }
-func (c *lazyClient) DeleteWLAN(ctx context.Context, site, id string) error {
+func (c *lazyClient) ListWLAN(ctx context.Context, site string) ([]unifi.WLAN, error) {
 	if err := c.init(ctx); err != nil {
-		return err
+		return nil, err
 	}
-	return c.inner.DeleteWLAN(ctx, site, id)
+	return c.inner.ListWLAN(ctx, site)
 }
-func (c *lazyClient) CreateWLAN(ctx context.Context, site string, d *unifi.WLAN) (*unifi.WLAN, error) {
+func (c *lazyClient) DeleteWLAN(ctx context.Context, site, id string) error {
 	if err := c.init(ctx); err != nil {
-		return nil, err
+		return err
 	}
-	return c.inner.CreateWLAN(ctx, site, d)
+	return c.inner.DeleteWLAN(ctx, site, id)
 }
 func (c *lazyClient) GetWLAN(ctx context.Context, site, id string) (*unifi.WLAN, error) {
 	if err := c.init(ctx); err != nil {
@@ -143,12 +245,6 @@ func (c *lazyClient) GetWLAN(ctx context.Context, site, id string) (*unifi.WLAN,
 	}
 	return c.inner.GetWLAN(ctx, site, id)
 }
-func (c *lazyClient) UpdateWLAN(ctx context.Context, site string, d *unifi.WLAN) (*unifi.WLAN, error) {
-	if err := c.init(ctx); err != nil {
-		return nil, err
-	}
-	return c.inner.UpdateWLAN(ctx, site, d)
-}
 func (c *lazyClient) DeleteUserGroup(ctx context.Context, site, id string) error {
 	if err := c.init(ctx); err != nil {
 		return err
@@ -305,6 +401,12 @@ func (c *lazyClient) UpdateFirewallRule(ctx context.Context, site string, d *uni
 	}
 	return c.inner.UpdateFirewallRule(ctx, site, d)
 }
+func (c *lazyClient) ListPortForward(ctx context.Context, site string) ([]unifi.PortForward, error) {
+	if err := c.init(ctx); err != nil {
+		return nil, err
+	}
+	return c.inner.ListPortForward(ctx, site)
+}
 func (c *lazyClient) GetPortForward(ctx context.Context, site, id string) (*unifi.PortForward, error) {
 	if err := c.init(ctx); err != nil {
 		return nil, err
@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/paultyng/go-unifi/unifi"
+)
+
+// These mirror the path styles in the SDK, they are needed to issue requests
+// for endpoints the SDK does not support yet.
+const (
+	apiPath      = "/api"
+	apiPathNew   = "/proxy/network/api"
+	apiV2Path    = "/v2/api"
+	apiV2PathNew = "/proxy/network/v2/api"
+)
+
+// discoverAPIV2Path uses the same heuristic as the SDK: UniFi OS consoles
+// return a 200 for the root path, older controllers redirect to /manage.
+func (c *lazyClient) discoverAPIV2Path(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	hc := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Transport: c.hc.Transport,
+	}
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to determine API URL style: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode == http.StatusOK {
+		return apiV2PathNew, nil
+	}
+	return apiV2Path, nil
+}
+
+// doV1 issues a request against the classic API, using the same path style as
+// the discovered v2 path.
+func (c *lazyClient) doV1(ctx context.Context, method, relativeURL string, reqBody interface{}, respBody interface{}) error {
+	base := apiPath
+	if c.apiV2Path == apiV2PathNew {
+		base = apiPathNew
+	}
+	return c.do(ctx, method, path.Join(base, relativeURL), reqBody, respBody)
+}
+
+// doV2 issues a request against the v2 API relative to the discovered v2 path.
+func (c *lazyClient) doV2(ctx context.Context, method, relativeURL string, reqBody interface{}, respBody interface{}) error {
+	return c.do(ctx, method, path.Join(c.apiV2Path, relativeURL), reqBody, respBody)
+}
+
+// do issues a raw request using the session of the SDK client, this is only
+// used for endpoints that are not yet implemented in the SDK.
+func (c *lazyClient) do(ctx context.Context, method, absPath string, reqBody interface{}, respBody interface{}) error {
+	// the SDK single threads requests for CSRF token propagation, so do the same
+	c.inner.Lock()
+	defer c.inner.Unlock()
+
+	var reqReader io.Reader
+	if reqBody != nil {
+		reqBytes, err := json.Marshal(reqBody)
+		if err != nil {
+			return fmt.Errorf("unable to marshal JSON: %s %s %w", method, absPath, err)
+		}
+		reqReader = bytes.NewReader(reqBytes)
+	}
+
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return err
+	}
+	reqURL := base.ResolveReference(&url.URL{Path: absPath})
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL.String(), reqReader)
+	if err != nil {
+		return fmt.Errorf("unable to create request: %s %s %w", method, absPath, err)
+	}
+
+	req.Header.Set("User-Agent", "terraform-provider-unifi/0.1")
+	req.Header.Add("Content-Type", "application/json; charset=utf-8")
+
+	if csrf := c.inner.CSRFToken(); csrf != "" {
+		req.Header.Set("X-CSRF-Token", csrf)
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to perform request: %s %s %w", method, absPath, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return &unifi.NotFoundError{}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		errBody := struct {
+			Meta struct {
+				RC      string `json:"rc"`
+				Message string `json:"msg"`
+			} `json:"meta"`
+		}{}
+		_ = json.NewDecoder(resp.Body).Decode(&errBody)
+		return fmt.Errorf("%w (%s) for %s %s", &unifi.APIError{
+			RC:      errBody.Meta.RC,
+			Message: errBody.Meta.Message,
+		}, resp.Status, method, reqURL.String())
+	}
+
+	if respBody == nil || resp.ContentLength == 0 {
+		return nil
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(respBody)
+	if err != nil {
+		return fmt.Errorf("unable to decode body: %s %s %w", method, absPath, err)
+	}
+
+	return nil
+}
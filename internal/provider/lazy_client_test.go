@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/paultyng/terraform-provider-unifi/internal/fakeunifi"
+)
+
+// newStandInController returns a minimal controller that supports login and
+// the status endpoint, just enough to initialize a lazyClient.
+func newStandInController(t *testing.T, tls bool, password, serverVersion string) *httptest.Server {
+	t.Helper()
+
+	if serverVersion == "" {
+		// not a controller at all
+		return httptest.NewServer(http.NotFoundHandler())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		// classic controllers redirect the root
+		http.Redirect(w, r, "/manage", http.StatusFound)
+	})
+	mux.HandleFunc("/api/login", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Password string `json:"password"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Password != password {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"meta":{"rc":"error","msg":"api.err.Invalid"},"data":[]}`)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "unifises", Value: "session"})
+		fmt.Fprint(w, `{"meta":{"rc":"ok"},"data":[]}`)
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"meta":{"rc":"ok","up":true,"server_version":%q},"data":[]}`, serverVersion)
+	})
+
+	if tls {
+		return httptest.NewTLSServer(mux)
+	}
+	return httptest.NewServer(mux)
+}
+
+func TestLazyClientVersion(t *testing.T) {
+	for _, c := range []struct {
+		name          string
+		tls           bool
+		insecure      bool
+		pathSuffix    string
+		unreachable   bool
+		password      string
+		serverVersion string
+
+		expectedKind    controllerErrorKind
+		expectedVersion string
+	}{
+		{name: "success", password: "pass", serverVersion: "6.0.43", expectedVersion: "6.0.43"},
+		{name: "login failure", password: "wrong", serverVersion: "6.0.43", expectedKind: controllerErrorLogin},
+		{name: "unparsable version", password: "pass", serverVersion: "not a version", expectedKind: controllerErrorVersion},
+		{name: "api path in base URL", pathSuffix: "/api", password: "pass", serverVersion: "6.0.43", expectedKind: controllerErrorBaseURL},
+		{name: "not a controller", password: "pass", expectedKind: controllerErrorBaseURL},
+		{name: "unreachable", unreachable: true, password: "pass", serverVersion: "6.0.43", expectedKind: controllerErrorBaseURL},
+		{name: "untrusted certificate", tls: true, password: "pass", serverVersion: "6.0.43", expectedKind: controllerErrorTLS},
+		{name: "insecure", tls: true, insecure: true, password: "pass", serverVersion: "6.0.43", expectedVersion: "6.0.43"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			srv := newStandInController(t, c.tls, "pass", c.serverVersion)
+			if c.unreachable {
+				srv.Close()
+			} else {
+				defer srv.Close()
+			}
+
+			lc := &lazyClient{
+				baseURL: srv.URL + c.pathSuffix,
+				user:    "user",
+				pass:    c.password,
+				httpConfig: httpClientConfig{
+					insecure: c.insecure,
+				},
+			}
+
+			v, err := lc.Version(context.Background())
+
+			if c.expectedVersion != "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %s", err)
+				}
+				if v.String() != c.expectedVersion {
+					t.Fatalf("expected version %q, got %q", c.expectedVersion, v)
+				}
+				return
+			}
+
+			var ce *controllerError
+			if !errors.As(err, &ce) {
+				t.Fatalf("expected controller error, got %#v", err)
+			}
+			if ce.kind != c.expectedKind {
+				t.Fatalf("expected error kind %d, got %d: %s", c.expectedKind, ce.kind, err)
+			}
+
+			diags := errorDiagnostics(err)
+			if len(diags) != 1 || diags[0].Summary != ce.summary() {
+				t.Fatalf("unexpected diagnostics: %#v", diags)
+			}
+
+			// subsequent calls should return the same error instead of panicking
+			if _, err2 := lc.Version(context.Background()); err2 != err {
+				t.Fatalf("expected the same error, got %s", err2)
+			}
+		})
+	}
+}
+
+func TestLazyClient_fake_expiredSession(t *testing.T) {
+	ctx := context.Background()
+	s := fakeunifi.NewServer()
+	defer s.Close()
+
+	lc := &lazyClient{
+		baseURL: s.URL,
+		user:    fakeunifi.DefaultUsername,
+		pass:    fakeunifi.DefaultPassword,
+	}
+
+	if _, err := lc.ListNetwork(ctx, "default"); err != nil {
+		t.Fatal(err)
+	}
+
+	s.ExpireSessions()
+
+	if _, err := lc.ListNetwork(ctx, "default"); err != nil {
+		t.Fatalf("expected the session to be renewed, got %s", err)
+	}
+	if logins := s.RequestCount("POST", "/api/login"); logins != 2 {
+		t.Fatalf("expected 2 logins, got %d", logins)
+	}
+}
+
+func TestControllerErrors_fake(t *testing.T) {
+	s := fakeunifi.NewServer()
+	defer s.Close()
+
+	c := &client{
+		c: &lazyClient{
+			baseURL: s.URL,
+			user:    fakeunifi.DefaultUsername,
+			pass:    "wrong",
+		},
+		site: "default",
+	}
+
+	// every resource and data source reports the failed login with its hint
+	p := New("test")()
+	resources := map[string]*schema.Resource{}
+	for name, r := range p.ResourcesMap {
+		resources[name] = r
+	}
+	for name, r := range p.DataSourcesMap {
+		resources["data."+name] = r
+	}
+	for name, r := range resources {
+		diags := r.ReadContext(context.Background(), r.Data(&terraform.InstanceState{ID: "id"}), c)
+		if len(diags) != 1 || diags[0].Summary != "Unable to log in to the Unifi controller" {
+			t.Errorf("%s: unexpected diagnostics: %#v", name, diags)
+		}
+	}
+}
@@ -0,0 +1,529 @@
+package provider
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/paultyng/go-unifi/unifi"
+)
+
+// defaultListCacheTTL bounds how stale a cached list can be, the cache is
+// mostly meant to collapse the identical list calls made while refreshing
+// many resources in a single run.
+const defaultListCacheTTL = 30 * time.Second
+
+type listCacheKey struct {
+	kind string
+	site string
+	// id is set for single objects the SDK looks up without listing
+	id string
+}
+
+type listCacheEntry struct {
+	// done is closed once value and err are set
+	done    chan struct{}
+	value   interface{}
+	err     error
+	expires time.Time
+}
+
+// listCache stores the results of list calls per object type and site.
+// Concurrent misses for the same key share a single request.
+type listCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[listCacheKey]*listCacheEntry
+	hits    int
+	misses  int
+}
+
+func newListCache(ttl time.Duration) *listCache {
+	return &listCache{
+		ttl:     ttl,
+		entries: map[listCacheKey]*listCacheEntry{},
+	}
+}
+
+func (lc *listCache) get(ctx context.Context, kind, site string, load func() (interface{}, error)) (interface{}, error) {
+	return lc.lookup(ctx, kind, site, "", load)
+}
+
+func (lc *listCache) lookup(ctx context.Context, kind, site, id string, load func() (interface{}, error)) (interface{}, error) {
+	key := listCacheKey{kind, site, id}
+
+	lc.mu.Lock()
+	e, ok := lc.entries[key]
+	if ok && (e.expires.IsZero() || time.Now().Before(e.expires)) {
+		lc.hits++
+		log.Printf("[TRACE] list cache hit for %s in site %q (hits: %d, misses: %d)", kind, site, lc.hits, lc.misses)
+		lc.mu.Unlock()
+
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if e.err == nil {
+			return e.value, nil
+		}
+		// the shared request failed, fall through and try again
+		lc.mu.Lock()
+	}
+
+	lc.misses++
+	log.Printf("[TRACE] list cache miss for %s in site %q (hits: %d, misses: %d)", kind, site, lc.hits, lc.misses)
+	e = &listCacheEntry{done: make(chan struct{})}
+	lc.entries[key] = e
+	lc.mu.Unlock()
+
+	value, err := load()
+
+	lc.mu.Lock()
+	e.value, e.err = value, err
+	e.expires = time.Now().Add(lc.ttl)
+	if err != nil && lc.entries[key] == e {
+		delete(lc.entries, key)
+	}
+	close(e.done)
+	lc.mu.Unlock()
+
+	return value, err
+}
+
+// invalidate drops the cached list and single objects of the type, it is
+// called on any write to the type.
+func (lc *listCache) invalidate(kind, site string) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	for key := range lc.entries {
+		if key.kind == kind && key.site == site {
+			delete(lc.entries, key)
+		}
+	}
+}
+
+// cachingClient wraps a unifiClient to cache list results for the duration
+// of a Terraform run. Get calls that the SDK implements by listing are also
+// served from the cache. Callers get copies of the cached objects, including
+// their nested slices, so they can modify them.
+type cachingClient struct {
+	unifiClient
+
+	cache *listCache
+}
+
+func newCachingClient(c unifiClient, ttl time.Duration) *cachingClient {
+	return &cachingClient{
+		unifiClient: c,
+		cache:       newListCache(ttl),
+	}
+}
+
+const (
+	listCacheUserGroup     = "usergroup"
+	listCacheFirewallGroup = "firewallgroup"
+	listCacheFirewallRule  = "firewallrule"
+	listCacheWLANGroup     = "wlangroup"
+	listCacheAPGroup       = "apgroup"
+	listCacheNetwork       = "networkconf"
+	listCacheDevice        = "device"
+	listCacheRADIUSProfile = "radiusprofile"
+	listCacheSite          = "site"
+	listCachePortProfile   = "portconf"
+	listCacheRouting       = "routing"
+	listCacheDynamicDNS    = "dynamicdns"
+	listCacheUser          = "user"
+)
+
+func (c *cachingClient) ListUserGroup(ctx context.Context, site string) ([]unifi.UserGroup, error) {
+	v, err := c.cache.get(ctx, listCacheUserGroup, site, func() (interface{}, error) {
+		return c.unifiClient.ListUserGroup(ctx, site)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]unifi.UserGroup(nil), v.([]unifi.UserGroup)...), nil
+}
+func (c *cachingClient) DeleteUserGroup(ctx context.Context, site, id string) error {
+	defer c.cache.invalidate(listCacheUserGroup, site)
+	return c.unifiClient.DeleteUserGroup(ctx, site, id)
+}
+func (c *cachingClient) CreateUserGroup(ctx context.Context, site string, d *unifi.UserGroup) (*unifi.UserGroup, error) {
+	defer c.cache.invalidate(listCacheUserGroup, site)
+	return c.unifiClient.CreateUserGroup(ctx, site, d)
+}
+func (c *cachingClient) UpdateUserGroup(ctx context.Context, site string, d *unifi.UserGroup) (*unifi.UserGroup, error) {
+	defer c.cache.invalidate(listCacheUserGroup, site)
+	return c.unifiClient.UpdateUserGroup(ctx, site, d)
+}
+
+func (c *cachingClient) ListFirewallGroup(ctx context.Context, site string) ([]unifi.FirewallGroup, error) {
+	v, err := c.cache.get(ctx, listCacheFirewallGroup, site, func() (interface{}, error) {
+		return c.unifiClient.ListFirewallGroup(ctx, site)
+	})
+	if err != nil {
+		return nil, err
+	}
+	groups := append([]unifi.FirewallGroup(nil), v.([]unifi.FirewallGroup)...)
+	for i := range groups {
+		groups[i].GroupMembers = append([]string(nil), groups[i].GroupMembers...)
+	}
+	return groups, nil
+}
+func (c *cachingClient) DeleteFirewallGroup(ctx context.Context, site, id string) error {
+	defer c.cache.invalidate(listCacheFirewallGroup, site)
+	return c.unifiClient.DeleteFirewallGroup(ctx, site, id)
+}
+func (c *cachingClient) CreateFirewallGroup(ctx context.Context, site string, d *unifi.FirewallGroup) (*unifi.FirewallGroup, error) {
+	defer c.cache.invalidate(listCacheFirewallGroup, site)
+	return c.unifiClient.CreateFirewallGroup(ctx, site, d)
+}
+func (c *cachingClient) UpdateFirewallGroup(ctx context.Context, site string, d *unifi.FirewallGroup) (*unifi.FirewallGroup, error) {
+	defer c.cache.invalidate(listCacheFirewallGroup, site)
+	return c.unifiClient.UpdateFirewallGroup(ctx, site, d)
+}
+
+func (c *cachingClient) ListFirewallRule(ctx context.Context, site string) ([]unifi.FirewallRule, error) {
+	v, err := c.cache.get(ctx, listCacheFirewallRule, site, func() (interface{}, error) {
+		return c.unifiClient.ListFirewallRule(ctx, site)
+	})
+	if err != nil {
+		return nil, err
+	}
+	rules := append([]unifi.FirewallRule(nil), v.([]unifi.FirewallRule)...)
+	for i := range rules {
+		rules[i].SrcFirewallGroupIDs = append([]string(nil), rules[i].SrcFirewallGroupIDs...)
+		rules[i].DstFirewallGroupIDs = append([]string(nil), rules[i].DstFirewallGroupIDs...)
+	}
+	return rules, nil
+}
+func (c *cachingClient) DeleteFirewallRule(ctx context.Context, site, id string) error {
+	defer c.cache.invalidate(listCacheFirewallRule, site)
+	return c.unifiClient.DeleteFirewallRule(ctx, site, id)
+}
+func (c *cachingClient) CreateFirewallRule(ctx context.Context, site string, d *unifi.FirewallRule) (*unifi.FirewallRule, error) {
+	defer c.cache.invalidate(listCacheFirewallRule, site)
+	return c.unifiClient.CreateFirewallRule(ctx, site, d)
+}
+func (c *cachingClient) UpdateFirewallRule(ctx context.Context, site string, d *unifi.FirewallRule) (*unifi.FirewallRule, error) {
+	defer c.cache.invalidate(listCacheFirewallRule, site)
+	return c.unifiClient.UpdateFirewallRule(ctx, site, d)
+}
+
+func (c *cachingClient) ListWLANGroup(ctx context.Context, site string) ([]unifi.WLANGroup, error) {
+	v, err := c.cache.get(ctx, listCacheWLANGroup, site, func() (interface{}, error) {
+		return c.unifiClient.ListWLANGroup(ctx, site)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]unifi.WLANGroup(nil), v.([]unifi.WLANGroup)...), nil
+}
+
+func (c *cachingClient) ListAPGroup(ctx context.Context, site string) ([]unifi.APGroup, error) {
+	v, err := c.cache.get(ctx, listCacheAPGroup, site, func() (interface{}, error) {
+		return c.unifiClient.ListAPGroup(ctx, site)
+	})
+	if err != nil {
+		return nil, err
+	}
+	groups := append([]unifi.APGroup(nil), v.([]unifi.APGroup)...)
+	for i := range groups {
+		groups[i].DeviceMACs = append([]string(nil), groups[i].DeviceMACs...)
+	}
+	return groups, nil
+}
+func (c *cachingClient) GetAPGroup(ctx context.Context, site, id string) (*unifi.APGroup, error) {
+	groups, err := c.ListAPGroup(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		if g.ID == id {
+			return &g, nil
+		}
+	}
+	return nil, &unifi.NotFoundError{}
+}
+func (c *cachingClient) CreateAPGroup(ctx context.Context, site string, d *unifi.APGroup) (*unifi.APGroup, error) {
+	defer c.cache.invalidate(listCacheAPGroup, site)
+	return c.unifiClient.CreateAPGroup(ctx, site, d)
+}
+func (c *cachingClient) UpdateAPGroup(ctx context.Context, site string, d *unifi.APGroup) (*unifi.APGroup, error) {
+	defer c.cache.invalidate(listCacheAPGroup, site)
+	return c.unifiClient.UpdateAPGroup(ctx, site, d)
+}
+func (c *cachingClient) DeleteAPGroup(ctx context.Context, site, id string) error {
+	defer c.cache.invalidate(listCacheAPGroup, site)
+	return c.unifiClient.DeleteAPGroup(ctx, site, id)
+}
+
+func (c *cachingClient) ListNetwork(ctx context.Context, site string) ([]unifi.Network, error) {
+	v, err := c.cache.get(ctx, listCacheNetwork, site, func() (interface{}, error) {
+		return c.unifiClient.ListNetwork(ctx, site)
+	})
+	if err != nil {
+		return nil, err
+	}
+	networks := append([]unifi.Network(nil), v.([]unifi.Network)...)
+	for i := range networks {
+		networks[i].NATOutboundIPAddresses = append([]unifi.NetworkNATOutboundIPAddresses(nil), networks[i].NATOutboundIPAddresses...)
+		networks[i].RemoteSiteSubnets = append([]string(nil), networks[i].RemoteSiteSubnets...)
+		networks[i].RemoteVPNSubnets = append([]string(nil), networks[i].RemoteVPNSubnets...)
+		networks[i].WANDHCPOptions = append([]unifi.NetworkWANDHCPOptions(nil), networks[i].WANDHCPOptions...)
+	}
+	return networks, nil
+}
+func (c *cachingClient) DeleteNetwork(ctx context.Context, site, id, name string) error {
+	defer c.cache.invalidate(listCacheNetwork, site)
+	return c.unifiClient.DeleteNetwork(ctx, site, id, name)
+}
+func (c *cachingClient) CreateNetwork(ctx context.Context, site string, d *unifi.Network) (*unifi.Network, error) {
+	defer c.cache.invalidate(listCacheNetwork, site)
+	return c.unifiClient.CreateNetwork(ctx, site, d)
+}
+func (c *cachingClient) UpdateNetwork(ctx context.Context, site string, d *unifi.Network) (*unifi.Network, error) {
+	defer c.cache.invalidate(listCacheNetwork, site)
+	return c.unifiClient.UpdateNetwork(ctx, site, d)
+}
+
+func (c *cachingClient) ListDevice(ctx context.Context, site string) ([]unifi.Device, error) {
+	v, err := c.cache.get(ctx, listCacheDevice, site, func() (interface{}, error) {
+		return c.unifiClient.ListDevice(ctx, site)
+	})
+	if err != nil {
+		return nil, err
+	}
+	devices := append([]unifi.Device(nil), v.([]unifi.Device)...)
+	for i := range devices {
+		copyDeviceSlices(&devices[i])
+	}
+	return devices, nil
+}
+func (c *cachingClient) GetDevice(ctx context.Context, site, id string) (*unifi.Device, error) {
+	devices, err := c.ListDevice(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range devices {
+		if d.ID == id {
+			return &d, nil
+		}
+	}
+	return nil, &unifi.NotFoundError{}
+}
+func (c *cachingClient) CreateDevice(ctx context.Context, site string, d *unifi.Device) (*unifi.Device, error) {
+	defer c.cache.invalidate(listCacheDevice, site)
+	return c.unifiClient.CreateDevice(ctx, site, d)
+}
+func (c *cachingClient) UpdateDevice(ctx context.Context, site string, d *unifi.Device) (*unifi.Device, error) {
+	defer c.cache.invalidate(listCacheDevice, site)
+	return c.unifiClient.UpdateDevice(ctx, site, d)
+}
+func (c *cachingClient) DeleteDevice(ctx context.Context, site, id string) error {
+	defer c.cache.invalidate(listCacheDevice, site)
+	return c.unifiClient.DeleteDevice(ctx, site, id)
+}
+func (c *cachingClient) AdoptDevice(ctx context.Context, site, mac string) error {
+	defer c.cache.invalidate(listCacheDevice, site)
+	return c.unifiClient.AdoptDevice(ctx, site, mac)
+}
+func (c *cachingClient) ForgetDevice(ctx context.Context, site, mac string) error {
+	defer c.cache.invalidate(listCacheDevice, site)
+	return c.unifiClient.ForgetDevice(ctx, site, mac)
+}
+func (c *cachingClient) SetDeviceDisabled(ctx context.Context, site, id string, disabled bool) error {
+	defer c.cache.invalidate(listCacheDevice, site)
+	return c.unifiClient.SetDeviceDisabled(ctx, site, id, disabled)
+}
+func (c *cachingClient) UpdateDevicePortOverrides(ctx context.Context, site, id string, overrides []devicePortOverride) ([]devicePortOverride, error) {
+	defer c.cache.invalidate(listCacheDevice, site)
+	return c.unifiClient.UpdateDevicePortOverrides(ctx, site, id, overrides)
+}
+
+func (c *cachingClient) GetUserByMAC(ctx context.Context, site, mac string) (*unifi.User, error) {
+	v, err := c.cache.lookup(ctx, listCacheUser, site, strings.ToLower(mac), func() (interface{}, error) {
+		return c.unifiClient.GetUserByMAC(ctx, site, mac)
+	})
+	if err != nil {
+		return nil, err
+	}
+	u := *v.(*unifi.User)
+	return &u, nil
+}
+func (c *cachingClient) CreateUser(ctx context.Context, site string, d *unifi.User) (*unifi.User, error) {
+	defer c.cache.invalidate(listCacheUser, site)
+	return c.unifiClient.CreateUser(ctx, site, d)
+}
+func (c *cachingClient) UpdateUser(ctx context.Context, site string, d *unifi.User) (*unifi.User, error) {
+	defer c.cache.invalidate(listCacheUser, site)
+	return c.unifiClient.UpdateUser(ctx, site, d)
+}
+func (c *cachingClient) BlockUserByMAC(ctx context.Context, site, mac string) error {
+	defer c.cache.invalidate(listCacheUser, site)
+	return c.unifiClient.BlockUserByMAC(ctx, site, mac)
+}
+func (c *cachingClient) UnblockUserByMAC(ctx context.Context, site, mac string) error {
+	defer c.cache.invalidate(listCacheUser, site)
+	return c.unifiClient.UnblockUserByMAC(ctx, site, mac)
+}
+func (c *cachingClient) DeleteUserByMAC(ctx context.Context, site, mac string) error {
+	defer c.cache.invalidate(listCacheUser, site)
+	return c.unifiClient.DeleteUserByMAC(ctx, site, mac)
+}
+
+func (c *cachingClient) ListRADIUSProfile(ctx context.Context, site string) ([]unifi.RADIUSProfile, error) {
+	v, err := c.cache.get(ctx, listCacheRADIUSProfile, site, func() (interface{}, error) {
+		return c.unifiClient.ListRADIUSProfile(ctx, site)
+	})
+	if err != nil {
+		return nil, err
+	}
+	profiles := append([]unifi.RADIUSProfile(nil), v.([]unifi.RADIUSProfile)...)
+	for i := range profiles {
+		profiles[i].AcctServers = append([]unifi.RADIUSProfileAcctServers(nil), profiles[i].AcctServers...)
+		profiles[i].AuthServers = append([]unifi.RADIUSProfileAuthServers(nil), profiles[i].AuthServers...)
+	}
+	return profiles, nil
+}
+func (c *cachingClient) DeleteRADIUSProfile(ctx context.Context, site, id string) error {
+	defer c.cache.invalidate(listCacheRADIUSProfile, site)
+	return c.unifiClient.DeleteRADIUSProfile(ctx, site, id)
+}
+func (c *cachingClient) CreateRADIUSProfile(ctx context.Context, site string, d *unifi.RADIUSProfile) (*unifi.RADIUSProfile, error) {
+	defer c.cache.invalidate(listCacheRADIUSProfile, site)
+	return c.unifiClient.CreateRADIUSProfile(ctx, site, d)
+}
+func (c *cachingClient) UpdateRADIUSProfile(ctx context.Context, site string, d *unifi.RADIUSProfile) (*unifi.RADIUSProfile, error) {
+	defer c.cache.invalidate(listCacheRADIUSProfile, site)
+	return c.unifiClient.UpdateRADIUSProfile(ctx, site, d)
+}
+
+// sites are not scoped to a site, so they are cached with an empty site
+func (c *cachingClient) ListSites(ctx context.Context) ([]unifi.Site, error) {
+	v, err := c.cache.get(ctx, listCacheSite, "", func() (interface{}, error) {
+		return c.unifiClient.ListSites(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]unifi.Site(nil), v.([]unifi.Site)...), nil
+}
+func (c *cachingClient) GetSite(ctx context.Context, id string) (*unifi.Site, error) {
+	sites, err := c.ListSites(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sites {
+		if s.ID == id {
+			return &s, nil
+		}
+	}
+	return nil, &unifi.NotFoundError{}
+}
+func (c *cachingClient) CreateSite(ctx context.Context, description string) ([]unifi.Site, error) {
+	defer c.cache.invalidate(listCacheSite, "")
+	return c.unifiClient.CreateSite(ctx, description)
+}
+func (c *cachingClient) UpdateSite(ctx context.Context, name, description string) ([]unifi.Site, error) {
+	defer c.cache.invalidate(listCacheSite, "")
+	return c.unifiClient.UpdateSite(ctx, name, description)
+}
+func (c *cachingClient) DeleteSite(ctx context.Context, id string) ([]unifi.Site, error) {
+	defer c.cache.invalidate(listCacheSite, "")
+	return c.unifiClient.DeleteSite(ctx, id)
+}
+
+func (c *cachingClient) ListPortProfile(ctx context.Context, site string) ([]unifi.PortProfile, error) {
+	v, err := c.cache.get(ctx, listCachePortProfile, site, func() (interface{}, error) {
+		return c.unifiClient.ListPortProfile(ctx, site)
+	})
+	if err != nil {
+		return nil, err
+	}
+	profiles := append([]unifi.PortProfile(nil), v.([]unifi.PortProfile)...)
+	for i := range profiles {
+		profiles[i].PortSecurityMACAddress = append([]string(nil), profiles[i].PortSecurityMACAddress...)
+		profiles[i].TaggedNetworkIDs = append([]string(nil), profiles[i].TaggedNetworkIDs...)
+	}
+	return profiles, nil
+}
+func (c *cachingClient) DeletePortProfile(ctx context.Context, site, id string) error {
+	defer c.cache.invalidate(listCachePortProfile, site)
+	return c.unifiClient.DeletePortProfile(ctx, site, id)
+}
+func (c *cachingClient) CreatePortProfile(ctx context.Context, site string, d *unifi.PortProfile) (*unifi.PortProfile, error) {
+	defer c.cache.invalidate(listCachePortProfile, site)
+	return c.unifiClient.CreatePortProfile(ctx, site, d)
+}
+func (c *cachingClient) UpdatePortProfile(ctx context.Context, site string, d *unifi.PortProfile) (*unifi.PortProfile, error) {
+	defer c.cache.invalidate(listCachePortProfile, site)
+	return c.unifiClient.UpdatePortProfile(ctx, site, d)
+}
+
+func (c *cachingClient) ListRouting(ctx context.Context, site string) ([]unifi.Routing, error) {
+	v, err := c.cache.get(ctx, listCacheRouting, site, func() (interface{}, error) {
+		return c.unifiClient.ListRouting(ctx, site)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]unifi.Routing(nil), v.([]unifi.Routing)...), nil
+}
+func (c *cachingClient) DeleteRouting(ctx context.Context, site, id string) error {
+	defer c.cache.invalidate(listCacheRouting, site)
+	return c.unifiClient.DeleteRouting(ctx, site, id)
+}
+func (c *cachingClient) CreateRouting(ctx context.Context, site string, d *unifi.Routing) (*unifi.Routing, error) {
+	defer c.cache.invalidate(listCacheRouting, site)
+	return c.unifiClient.CreateRouting(ctx, site, d)
+}
+func (c *cachingClient) UpdateRouting(ctx context.Context, site string, d *unifi.Routing) (*unifi.Routing, error) {
+	defer c.cache.invalidate(listCacheRouting, site)
+	return c.unifiClient.UpdateRouting(ctx, site, d)
+}
+
+func (c *cachingClient) ListDynamicDNS(ctx context.Context, site string) ([]unifi.DynamicDNS, error) {
+	v, err := c.cache.get(ctx, listCacheDynamicDNS, site, func() (interface{}, error) {
+		return c.unifiClient.ListDynamicDNS(ctx, site)
+	})
+	if err != nil {
+		return nil, err
+	}
+	configs := append([]unifi.DynamicDNS(nil), v.([]unifi.DynamicDNS)...)
+	for i := range configs {
+		configs[i].Options = append([]string(nil), configs[i].Options...)
+	}
+	return configs, nil
+}
+func (c *cachingClient) DeleteDynamicDNS(ctx context.Context, site, id string) error {
+	defer c.cache.invalidate(listCacheDynamicDNS, site)
+	return c.unifiClient.DeleteDynamicDNS(ctx, site, id)
+}
+func (c *cachingClient) CreateDynamicDNS(ctx context.Context, site string, d *unifi.DynamicDNS) (*unifi.DynamicDNS, error) {
+	defer c.cache.invalidate(listCacheDynamicDNS, site)
+	return c.unifiClient.CreateDynamicDNS(ctx, site, d)
+}
+func (c *cachingClient) UpdateDynamicDNS(ctx context.Context, site string, d *unifi.DynamicDNS) (*unifi.DynamicDNS, error) {
+	defer c.cache.invalidate(listCacheDynamicDNS, site)
+	return c.unifiClient.UpdateDynamicDNS(ctx, site, d)
+}
+
+// copyDeviceSlices replaces the slices of a device with copies, so callers can
+// modify the device without changing the cached list.
+func copyDeviceSlices(d *unifi.Device) {
+	d.EthernetOverrides = append([]unifi.DeviceEthernetOverrides(nil), d.EthernetOverrides...)
+	d.OutletOverrides = append([]unifi.DeviceOutletOverrides(nil), d.OutletOverrides...)
+	d.RadioTable = append([]unifi.DeviceRadioTable(nil), d.RadioTable...)
+	d.WLANOverrides = append([]unifi.DeviceWLANOverrides(nil), d.WLANOverrides...)
+	d.RpsOverride.RpsPortTable = append([]unifi.DeviceRpsPortTable(nil), d.RpsOverride.RpsPortTable...)
+
+	d.PortOverrides = append([]unifi.DevicePortOverrides(nil), d.PortOverrides...)
+	for i := range d.PortOverrides {
+		d.PortOverrides[i].PortSecurityMACAddress = append([]string(nil), d.PortOverrides[i].PortSecurityMACAddress...)
+	}
+}
@@ -0,0 +1,288 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/paultyng/go-unifi/unifi"
+)
+
+func TestListCache(t *testing.T) {
+	ctx := context.Background()
+	lc := newListCache(time.Minute)
+
+	var loads int32
+	load := func() (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		return []string{"a"}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := lc.get(ctx, "kind", "default", load); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := lc.get(ctx, "kind", "other", load); err != nil {
+		t.Fatal(err)
+	}
+	if actual := atomic.LoadInt32(&loads); actual != 2 {
+		t.Fatalf("expected 2 loads, got %d", actual)
+	}
+
+	lc.invalidate("kind", "default")
+	if _, err := lc.get(ctx, "kind", "default", load); err != nil {
+		t.Fatal(err)
+	}
+	if actual := atomic.LoadInt32(&loads); actual != 3 {
+		t.Fatalf("expected 3 loads after invalidation, got %d", actual)
+	}
+	if lc.hits != 2 || lc.misses != 3 {
+		t.Fatalf("expected 2 hits and 3 misses, got %d and %d", lc.hits, lc.misses)
+	}
+}
+
+func TestListCache_expiry(t *testing.T) {
+	ctx := context.Background()
+	lc := newListCache(time.Millisecond)
+
+	var loads int32
+	load := func() (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		return nil, nil
+	}
+
+	if _, err := lc.get(ctx, "kind", "default", load); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := lc.get(ctx, "kind", "default", load); err != nil {
+		t.Fatal(err)
+	}
+	if actual := atomic.LoadInt32(&loads); actual != 2 {
+		t.Fatalf("expected 2 loads, got %d", actual)
+	}
+}
+
+func TestListCache_errorsNotCached(t *testing.T) {
+	ctx := context.Background()
+	lc := newListCache(time.Minute)
+
+	expected := errors.New("boom")
+	if _, err := lc.get(ctx, "kind", "default", func() (interface{}, error) { return nil, expected }); err != expected {
+		t.Fatalf("expected error %q, got %v", expected, err)
+	}
+	v, err := lc.get(ctx, "kind", "default", func() (interface{}, error) { return "ok", nil })
+	if err != nil || v != "ok" {
+		t.Fatalf("expected a new load after an error, got %v, %v", v, err)
+	}
+}
+
+func TestListCache_concurrentMisses(t *testing.T) {
+	ctx := context.Background()
+	lc := newListCache(time.Minute)
+
+	var loads int32
+	release := make(chan struct{})
+	load := func() (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return nil, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := lc.get(ctx, "kind", "default", load); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	// give the goroutines a chance to queue up behind the first load
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if actual := atomic.LoadInt32(&loads); actual != 1 {
+		t.Fatalf("expected 1 load, got %d", actual)
+	}
+}
+
+func TestCachingClient_fake(t *testing.T) {
+	ctx := context.Background()
+	c, s := newFakeClient(t)
+
+	id, err := s.AddObject("default", "device", unifi.Device{
+		MAC:           "00:00:5e:00:53:40",
+		Name:          "ap",
+		PortOverrides: []unifi.DevicePortOverrides{{PortIDX: 1, Name: "uplink"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := c.c.GetDevice(ctx, "default", id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.c.ListDevice(ctx, "default"); err != nil {
+		t.Fatal(err)
+	}
+	if actual := s.RequestCount("GET", "/api/s/default/stat/device"); actual != 1 {
+		t.Fatalf("expected 1 list request, got %d", actual)
+	}
+
+	d, err := c.c.GetDevice(ctx, "default", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Name = "renamed"
+	if _, err := c.c.UpdateDevice(ctx, "default", d); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err = c.c.GetDevice(ctx, "default", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Name != "renamed" {
+		t.Fatalf("expected the cache to be invalidated on update, got name %q", d.Name)
+	}
+	if actual := s.RequestCount("GET", "/api/s/default/stat/device"); actual != 2 {
+		t.Fatalf("expected 2 list requests, got %d", actual)
+	}
+
+	d.PortOverrides[0].Name = "changed"
+	d, err = c.c.GetDevice(ctx, "default", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := d.PortOverrides[0].Name; actual != "uplink" {
+		t.Fatalf("expected the cached port override to be unchanged, got name %q", actual)
+	}
+}
+
+func TestCachingClient_fakeUser(t *testing.T) {
+	ctx := context.Background()
+	c, s := newFakeClient(t)
+
+	const mac = "00:00:5e:00:53:41"
+	if _, err := s.AddObject("default", "user", unifi.User{MAC: mac, Name: "client"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.c.GetUserByMAC(ctx, "default", mac); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if actual := s.RequestCount("GET", "/api/s/default/stat/user/"+mac); actual != 1 {
+		t.Fatalf("expected 1 lookup request, got %d", actual)
+	}
+
+	if err := c.c.BlockUserByMAC(ctx, "default", mac); err != nil {
+		t.Fatal(err)
+	}
+	u, err := c.c.GetUserByMAC(ctx, "default", mac)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !u.Blocked {
+		t.Fatal("expected the cache to be invalidated on block")
+	}
+	if actual := s.RequestCount("GET", "/api/s/default/stat/user/"+mac); actual != 2 {
+		t.Fatalf("expected 2 lookup requests, got %d", actual)
+	}
+}
+
+func TestCachingClient_fakeCopies(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		collection string
+		object     interface{}
+		// mutate changes a nested value of the first listed object and
+		// returns the value it had
+		mutate func(c unifiClient) (string, error)
+	}{
+		{"networkconf", unifi.Network{Name: "vpn", RemoteVPNSubnets: []string{"10.1.0.0/24"}}, func(c unifiClient) (string, error) {
+			networks, err := c.ListNetwork(ctx, "default")
+			if err != nil {
+				return "", err
+			}
+			old := networks[0].RemoteVPNSubnets[0]
+			networks[0].RemoteVPNSubnets[0] = "changed"
+			return old, nil
+		}},
+		{"firewallrule", unifi.FirewallRule{Name: "rule", SrcFirewallGroupIDs: []string{"group"}}, func(c unifiClient) (string, error) {
+			rules, err := c.ListFirewallRule(ctx, "default")
+			if err != nil {
+				return "", err
+			}
+			old := rules[0].SrcFirewallGroupIDs[0]
+			rules[0].SrcFirewallGroupIDs[0] = "changed"
+			return old, nil
+		}},
+		{"firewallgroup", unifi.FirewallGroup{Name: "group", GroupMembers: []string{"10.0.0.1"}}, func(c unifiClient) (string, error) {
+			groups, err := c.ListFirewallGroup(ctx, "default")
+			if err != nil {
+				return "", err
+			}
+			old := groups[0].GroupMembers[0]
+			groups[0].GroupMembers[0] = "changed"
+			return old, nil
+		}},
+		{"radiusprofile", unifi.RADIUSProfile{Name: "radius", AuthServers: []unifi.RADIUSProfileAuthServers{{IP: "10.0.0.2"}}}, func(c unifiClient) (string, error) {
+			profiles, err := c.ListRADIUSProfile(ctx, "default")
+			if err != nil {
+				return "", err
+			}
+			old := profiles[0].AuthServers[0].IP
+			profiles[0].AuthServers[0].IP = "changed"
+			return old, nil
+		}},
+		{"portconf", unifi.PortProfile{Name: "profile", TaggedNetworkIDs: []string{"network"}}, func(c unifiClient) (string, error) {
+			profiles, err := c.ListPortProfile(ctx, "default")
+			if err != nil {
+				return "", err
+			}
+			old := profiles[0].TaggedNetworkIDs[0]
+			profiles[0].TaggedNetworkIDs[0] = "changed"
+			return old, nil
+		}},
+		{"dynamicdns", unifi.DynamicDNS{Service: "dyndns", Options: []string{"option"}}, func(c unifiClient) (string, error) {
+			configs, err := c.ListDynamicDNS(ctx, "default")
+			if err != nil {
+				return "", err
+			}
+			old := configs[0].Options[0]
+			configs[0].Options[0] = "changed"
+			return old, nil
+		}},
+	} {
+		t.Run(tc.collection, func(t *testing.T) {
+			c, s := newFakeClient(t)
+			if _, err := s.AddObject("default", tc.collection, tc.object); err != nil {
+				t.Fatal(err)
+			}
+
+			expected, err := tc.mutate(c.c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := tc.mutate(c.c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != expected {
+				t.Fatalf("expected the cached value to be unchanged, got %q", actual)
+			}
+		})
+	}
+}
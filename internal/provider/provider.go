@@ -4,9 +4,11 @@
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/paultyng/go-unifi/unifi"
 )
 
@@ -15,6 +17,10 @@
 	controllerV6 = version.Must(version.NewVersion("6.0.0"))
 )
 
+// defaultTimeout applies to every resource operation unless it is overridden
+// in the resource's timeouts block.
+const defaultTimeout = 5 * time.Minute
+
 func init() {
 	schema.DescriptionKind = schema.StringMarkdown
 
@@ -36,18 +42,26 @@ func New(version string) func() *schema.Provider {
 			Schema: map[string]*schema.Schema{
 				"username": {
 					Description: "Local user name for the Unifi controller API. Can be specified with the `UNIFI_USERNAME` " +
-						"environment variable.",
+						"environment variable. Required unless `api_key` is set.",
 					Type:        schema.TypeString,
-					Required:    true,
+					Optional:    true,
 					DefaultFunc: schema.EnvDefaultFunc("UNIFI_USERNAME", ""),
 				},
 				"password": {
 					Description: "Password for the user accessing the API. Can be specified with the `UNIFI_PASSWORD` " +
-						"environment variable.",
+						"environment variable. Required unless `api_key` is set.",
 					Type:        schema.TypeString,
-					Required:    true,
+					Optional:    true,
 					DefaultFunc: schema.EnvDefaultFunc("UNIFI_PASSWORD", ""),
 				},
+				"api_key": {
+					Description: "API key for UniFi OS controllers, sent in the `X-API-KEY` header instead of logging in " +
+						"with `username` and `password`. Can be specified with the `UNIFI_API_KEY` environment variable.",
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					DefaultFunc: schema.EnvDefaultFunc("UNIFI_API_KEY", ""),
+				},
 				"api_url": {
 					Description: "URL of the controller API. Can be specified with the `UNIFI_API` environment variable. " +
 						"You should **NOT** supply the path (`/api`), the SDK will discover the appropriate paths. This is " +
@@ -72,32 +86,112 @@ func New(version string) func() *schema.Provider {
 					Optional:    true,
 					DefaultFunc: schema.EnvDefaultFunc("UNIFI_INSECURE", false),
 				},
+				"ca_cert_file": {
+					Description: "Path to a PEM encoded CA certificate bundle to trust in addition to the system roots " +
+						"when verifying the controller certificate. Can be specified with the `UNIFI_CA_CERT_FILE` " +
+						"environment variable.",
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("UNIFI_CA_CERT_FILE", ""),
+				},
+				"ca_cert_pem": {
+					Description: "PEM encoded CA certificates to trust in addition to the system roots when verifying " +
+						"the controller certificate.",
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"tls_fingerprint_sha256": {
+					Description: "SHA-256 fingerprint of the controller certificate, in hex optionally separated by colons. " +
+						"When set, the certificate presented by the controller must match this fingerprint, even if " +
+						"`allow_insecure` is `true`, and chain and host name verification are skipped, which allows " +
+						"self-signed certificates. Can be specified with the `UNIFI_TLS_FINGERPRINT_SHA256` environment variable.",
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("UNIFI_TLS_FINGERPRINT_SHA256", ""),
+					ValidateFunc: validateFingerprint,
+				},
+				"max_retries": {
+					Description: "Maximum number of times a request is retried when the controller is unavailable or " +
+						"returns a server error. Set to `0` to disable retries. An expired session always triggers a " +
+						"single login and retry of the request. Can be specified with the `UNIFI_MAX_RETRIES` environment variable.",
+					Type:         schema.TypeInt,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("UNIFI_MAX_RETRIES", defaultMaxRetries),
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				"retry_wait_min": {
+					Description: "Initial wait between retries, doubled on each attempt (for example `500ms` or `1s`). Must not be " +
+						"greater than `retry_wait_max`.",
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      defaultRetryWaitMin.String(),
+					ValidateFunc: validateDuration,
+				},
+				"retry_wait_max": {
+					Description:  "Maximum wait between retries (for example `30s`).",
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      defaultRetryWaitMax.String(),
+					ValidateFunc: validateDuration,
+				},
+				"max_requests_per_second": {
+					Description: "Maximum number of requests per second sent to the controller. Requests over the limit " +
+						"are queued in order. Set to `0` for no limit. Can be specified with the " +
+						"`UNIFI_MAX_REQUESTS_PER_SECOND` environment variable.",
+					Type:         schema.TypeFloat,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("UNIFI_MAX_REQUESTS_PER_SECOND", 0.0),
+					ValidateFunc: validation.FloatAtLeast(0),
+				},
+				"max_concurrent_requests": {
+					Description: "Maximum number of requests to the controller in flight at the same time. Requests over " +
+						"the limit are queued in order. Set to `0` for no limit. Can be specified with the " +
+						"`UNIFI_MAX_CONCURRENT_REQUESTS` environment variable.",
+					Type:         schema.TypeInt,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("UNIFI_MAX_CONCURRENT_REQUESTS", 0),
+					ValidateFunc: validation.IntAtLeast(0),
+				},
 			},
 			DataSourcesMap: map[string]*schema.Resource{
 				"unifi_ap_group":       dataAPGroup(),
+				"unifi_device":         dataDevice(),
+				"unifi_devices":        dataDevices(),
+				"unifi_network":        dataNetwork(),
+				"unifi_networks":       dataNetworks(),
 				"unifi_port_profile":   dataPortProfile(),
 				"unifi_radius_profile": dataRADIUSProfile(),
 				"unifi_user_group":     dataUserGroup(),
 				"unifi_wlan_group":     dataWLANGroup(),
 			},
 			ResourcesMap: map[string]*schema.Resource{
-				// TODO: "unifi_ap_group"
-				"unifi_device":         resourceDevice(),
-				"unifi_dynamic_dns":    resourceDynamicDNS(),
-				"unifi_firewall_group": resourceFirewallGroup(),
-				"unifi_firewall_rule":  resourceFirewallRule(),
-				"unifi_network":        resourceNetwork(),
-				"unifi_port_forward":   resourcePortForward(),
-				"unifi_port_profile":   resourcePortProfile(),
-				"unifi_site":           resourceSite(),
-				"unifi_static_route":   resourceStaticRoute(),
-				"unifi_user_group":     resourceUserGroup(),
-				"unifi_user":           resourceUser(),
-				"unifi_wlan":           resourceWLAN(),
-				"unifi_setting_mgmt":   resourceSettingMgmt(),
+				"unifi_ap_group":         resourceAPGroup(),
+				"unifi_device":           resourceDevice(),
+				"unifi_dynamic_dns":      resourceDynamicDNS(),
+				"unifi_firewall_group":   resourceFirewallGroup(),
+				"unifi_firewall_rule":    resourceFirewallRule(),
+				"unifi_firewall_ruleset": resourceFirewallRuleset(),
+				"unifi_network":          resourceNetwork(),
+				"unifi_port_forward":     resourcePortForward(),
+				"unifi_port_profile":     resourcePortProfile(),
+				"unifi_radius_profile":   resourceRADIUSProfile(),
+				"unifi_site":             resourceSite(),
+				"unifi_static_route":     resourceStaticRoute(),
+				"unifi_user_group":       resourceUserGroup(),
+				"unifi_user":             resourceUser(),
+				"unifi_wlan":             resourceWLAN(),
+				"unifi_setting_mgmt":     resourceSettingMgmt(),
 			},
 		}
 
+		for name, r := range p.ResourcesMap {
+			r.CustomizeDiff = customizeDiffCapabilities(name, r.CustomizeDiff)
+			withControllerErrors(r)
+		}
+		for _, r := range p.DataSourcesMap {
+			withControllerErrors(r)
+		}
+
 		p.ConfigureFunc = configure(version, p)
 		return p
 	}
@@ -107,17 +201,58 @@ func configure(version string, p *schema.Provider) schema.ConfigureFunc {
 	return func(d *schema.ResourceData) (interface{}, error) {
 		user := d.Get("username").(string)
 		pass := d.Get("password").(string)
+		apiKey := d.Get("api_key").(string)
 		baseURL := d.Get("api_url").(string)
 		site := d.Get("site").(string)
 		insecure := d.Get("allow_insecure").(bool)
 
+		rootCAs, err := loadCertPool(d.Get("ca_cert_file").(string), d.Get("ca_cert_pem").(string))
+		if err != nil {
+			return nil, err
+		}
+
+		var fingerprint []byte
+		if v := d.Get("tls_fingerprint_sha256").(string); v != "" {
+			fingerprint, err = parseFingerprint(v)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		maxRetries := d.Get("max_retries").(int)
+
+		// these are already validated
+		retryWaitMin, _ := time.ParseDuration(d.Get("retry_wait_min").(string))
+		retryWaitMax, _ := time.ParseDuration(d.Get("retry_wait_max").(string))
+		if retryWaitMin > retryWaitMax {
+			return nil, fmt.Errorf("retry_wait_min (%s) must not be greater than retry_wait_max (%s)", retryWaitMin, retryWaitMax)
+		}
+
+		maxRequestsPerSecond := d.Get("max_requests_per_second").(float64)
+		maxConcurrentRequests := d.Get("max_concurrent_requests").(int)
+
+		if apiKey == "" && (user == "" || pass == "") {
+			return nil, fmt.Errorf("either api_key or both username and password must be set")
+		}
+
 		c := &client{
-			c: &lazyClient{
-				user:     user,
-				pass:     pass,
-				baseURL:  baseURL,
-				insecure: insecure,
-			},
+			c: newCachingClient(&lazyClient{
+				user:    user,
+				pass:    pass,
+				baseURL: baseURL,
+				httpConfig: httpClientConfig{
+					insecure:     insecure,
+					rootCAs:      rootCAs,
+					fingerprint:  fingerprint,
+					apiKey:       apiKey,
+					maxRetries:   maxRetries,
+					retryWaitMin: retryWaitMin,
+					retryWaitMax: retryWaitMax,
+
+					maxRequestsPerSecond:  maxRequestsPerSecond,
+					maxConcurrentRequests: maxConcurrentRequests,
+				},
+			}, defaultListCacheTTL),
 			site: site,
 		}
 
@@ -126,7 +261,7 @@ func configure(version string, p *schema.Provider) schema.ConfigureFunc {
 }
 
 type unifiClient interface {
-	Version() string
+	Version(ctx context.Context) (*version.Version, error)
 
 	ListUserGroup(ctx context.Context, site string) ([]unifi.UserGroup, error)
 	DeleteUserGroup(ctx context.Context, site, id string) error
@@ -149,6 +284,10 @@ type unifiClient interface {
 	ListWLANGroup(ctx context.Context, site string) ([]unifi.WLANGroup, error)
 
 	ListAPGroup(ctx context.Context, site string) ([]unifi.APGroup, error)
+	GetAPGroup(ctx context.Context, site, id string) (*unifi.APGroup, error)
+	CreateAPGroup(ctx context.Context, site string, d *unifi.APGroup) (*unifi.APGroup, error)
+	UpdateAPGroup(ctx context.Context, site string, d *unifi.APGroup) (*unifi.APGroup, error)
+	DeleteAPGroup(ctx context.Context, site, id string) error
 
 	DeleteNetwork(ctx context.Context, site, id, name string) error
 	CreateNetwork(ctx context.Context, site string, d *unifi.Network) (*unifi.Network, error)
@@ -156,16 +295,25 @@ type unifiClient interface {
 	ListNetwork(ctx context.Context, site string) ([]unifi.Network, error)
 	UpdateNetwork(ctx context.Context, site string, d *unifi.Network) (*unifi.Network, error)
 
+	ListWLAN(ctx context.Context, site string) ([]unifi.WLAN, error)
 	DeleteWLAN(ctx context.Context, site, id string) error
 	CreateWLAN(ctx context.Context, site string, d *unifi.WLAN) (*unifi.WLAN, error)
 	GetWLAN(ctx context.Context, site, id string) (*unifi.WLAN, error)
 	UpdateWLAN(ctx context.Context, site string, d *unifi.WLAN) (*unifi.WLAN, error)
+	GetWLANExtra(ctx context.Context, site, id string) (*wlanExtra, error)
+	UpdateWLANExtra(ctx context.Context, site, id string, d *wlanExtra) (*wlanExtra, error)
 
 	GetDevice(ctx context.Context, site, id string) (*unifi.Device, error)
 	CreateDevice(ctx context.Context, site string, d *unifi.Device) (*unifi.Device, error)
 	UpdateDevice(ctx context.Context, site string, d *unifi.Device) (*unifi.Device, error)
 	DeleteDevice(ctx context.Context, site, id string) error
 	ListDevice(ctx context.Context, site string) ([]unifi.Device, error)
+	ListDeviceStatus(ctx context.Context, site string) ([]deviceStatus, error)
+	AdoptDevice(ctx context.Context, site, mac string) error
+	ForgetDevice(ctx context.Context, site, mac string) error
+	SetDeviceDisabled(ctx context.Context, site, id string, disabled bool) error
+	GetDevicePortOverrides(ctx context.Context, site, id string) ([]devicePortOverride, error)
+	UpdateDevicePortOverrides(ctx context.Context, site, id string, overrides []devicePortOverride) ([]devicePortOverride, error)
 
 	GetUser(ctx context.Context, site, id string) (*unifi.User, error)
 	GetUserByMAC(ctx context.Context, site, mac string) (*unifi.User, error)
@@ -175,6 +323,7 @@ type unifiClient interface {
 	UpdateUser(ctx context.Context, site string, d *unifi.User) (*unifi.User, error)
 	DeleteUserByMAC(ctx context.Context, site, mac string) error
 
+	ListPortForward(ctx context.Context, site string) ([]unifi.PortForward, error)
 	GetPortForward(ctx context.Context, site, id string) (*unifi.PortForward, error)
 	DeletePortForward(ctx context.Context, site, id string) error
 	CreatePortForward(ctx context.Context, site string, d *unifi.PortForward) (*unifi.PortForward, error)
@@ -219,6 +368,6 @@ type client struct {
 	site string
 }
 
-func (c *client) ControllerVersion() *version.Version {
-	return version.Must(version.NewVersion(c.c.Version()))
+func (c *client) ControllerVersion(ctx context.Context) (*version.Version, error) {
+	return c.c.Version(ctx)
 }
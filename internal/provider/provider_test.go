@@ -30,11 +30,15 @@ func TestMain(m *testing.M) {
 
 	user := os.Getenv("UNIFI_USERNAME")
 	pass := os.Getenv("UNIFI_PASSWORD")
+	apiKey := os.Getenv("UNIFI_API_KEY")
 	baseURL := os.Getenv("UNIFI_API")
 	insecure := os.Getenv("UNIFI_INSECURE") == "true"
 
 	testClient = &unifi.Client{}
-	setHTTPClient(testClient, insecure)
+	setHTTPClient(testClient, httpClientConfig{
+		insecure: insecure,
+		apiKey:   apiKey,
+	})
 	testClient.SetBaseURL(baseURL)
 	err := testClient.Login(context.Background(), user, pass)
 	if err != nil {
@@ -72,10 +76,11 @@ func siteAndIDImportStateIDFunc(resourceName string) func(*terraform.State) (str
 
 func preCheck(t *testing.T) {
 	variables := []string{
-		"UNIFI_USERNAME",
-		"UNIFI_PASSWORD",
 		"UNIFI_API",
 	}
+	if os.Getenv("UNIFI_API_KEY") == "" {
+		variables = append(variables, "UNIFI_USERNAME", "UNIFI_PASSWORD")
+	}
 
 	for _, variable := range variables {
 		value := os.Getenv(variable)
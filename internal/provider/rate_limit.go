@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimitTransport limits the rate and the number of concurrent requests
+// sent to the controller. Requests are served in the order they arrive: slots
+// are reserved in arrival order and blocked senders on a channel are queued
+// first in, first out.
+type rateLimitTransport struct {
+	next http.RoundTripper
+
+	// interval is the minimum time between the start of two requests, zero
+	// disables the rate limit
+	interval time.Duration
+	// sem holds a token for each request in flight, nil disables the limit
+	sem chan struct{}
+
+	mu       sync.Mutex
+	nextSlot time.Time
+}
+
+func newRateLimitTransport(next http.RoundTripper, maxRequestsPerSecond float64, maxConcurrentRequests int) *rateLimitTransport {
+	t := &rateLimitTransport{
+		next: next,
+	}
+	if maxRequestsPerSecond > 0 {
+		t.interval = time.Duration(float64(time.Second) / maxRequestsPerSecond)
+	}
+	if maxConcurrentRequests > 0 {
+		t.sem = make(chan struct{}, maxConcurrentRequests)
+	}
+	return t
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	if t.sem != nil {
+		select {
+		case t.sem <- struct{}{}:
+		default:
+			start := time.Now()
+			select {
+			case t.sem <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			log.Printf("[DEBUG] Unifi request %s %s waited %s for a free connection slot", req.Method, req.URL.Path, time.Since(start))
+		}
+	}
+
+	if wait := t.reserve(); wait > 0 {
+		log.Printf("[DEBUG] Unifi request %s %s waiting %s for rate limit", req.Method, req.URL.Path, wait)
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			t.release()
+			return nil, ctx.Err()
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.release()
+		return nil, err
+	}
+
+	// the request is in flight until its body is closed
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: t.release}
+	return resp, nil
+}
+
+// reserve returns how long to wait for the next free slot.
+func (t *rateLimitTransport) reserve() time.Duration {
+	if t.interval == 0 {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	slot := t.nextSlot
+	if slot.Before(now) {
+		slot = now
+	}
+	t.nextSlot = slot.Add(t.interval)
+	return slot.Sub(now)
+}
+
+func (t *rateLimitTransport) release() {
+	if t.sem != nil {
+		<-t.sem
+	}
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testRateLimitGet(t *testing.T, hc *http.Client, url string) {
+	t.Helper()
+
+	resp, err := hc.Get(url)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+}
+
+func TestRateLimitTransport_rate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	hc := &http.Client{Transport: newRateLimitTransport(http.DefaultTransport, 20, 0)}
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		testRateLimitGet(t, hc, srv.URL)
+	}
+	// the first request is not delayed, the other 4 are 50ms apart
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("expected requests to take at least 200ms, took %s", elapsed)
+	}
+}
+
+func TestRateLimitTransport_concurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	hc := &http.Client{Transport: newRateLimitTransport(http.DefaultTransport, 0, 2)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			testRateLimitGet(t, hc, srv.URL)
+		}()
+	}
+	wg.Wait()
+
+	if actual := atomic.LoadInt32(&maxInFlight); actual != 2 {
+		t.Fatalf("expected at most 2 concurrent requests, got %d", actual)
+	}
+}
+
+func TestRateLimitTransport_fair(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		served []int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i, _ := strconv.Atoi(r.URL.Query().Get("i"))
+		mu.Lock()
+		served = append(served, i)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	hc := &http.Client{Transport: newRateLimitTransport(http.DefaultTransport, 0, 1)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			testRateLimitGet(t, hc, srv.URL+"?i="+strconv.Itoa(i))
+		}(i)
+		// make sure the requests queue up in order
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+
+	for i, actual := range served {
+		if actual != i {
+			t.Fatalf("expected requests to be served in order, got %v", served)
+		}
+	}
+}
+
+func TestRateLimitTransport_canceled(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	hc := &http.Client{Transport: newRateLimitTransport(http.DefaultTransport, 0, 1)}
+
+	go testRateLimitGet(t, hc, srv.URL)
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hc.Do(req); err == nil {
+		t.Fatal("expected the queued request to be canceled")
+	}
+}
@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/logging"
+)
+
+const redactedValue = "REDACTED"
+
+// sensitiveHeaders are masked entirely, except for cookie names.
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+	"X-Api-Key":     true,
+	"X-Csrf-Token":  true,
+}
+
+// sensitiveKeyParts mark a JSON key as sensitive when they appear anywhere in
+// it. The controller also prefixes secrets (x_passphrase, x_secret, etc.) with
+// "x_", so all of those are masked as well.
+var sensitiveKeyParts = []string{
+	"password",
+	"passwd",
+	"passphrase",
+	"secret",
+	"token",
+	"private_key",
+	"api_key",
+	"apikey",
+}
+
+// loggingTransport logs requests and responses at the DEBUG level in the same
+// format as the SDK's logging transport, but masks credentials, secrets and
+// session cookies so the output can be attached to bug reports.
+type loggingTransport struct {
+	name string
+	next http.RoundTripper
+}
+
+func newLoggingTransport(name string, next http.RoundTripper) *loggingTransport {
+	return &loggingTransport{
+		name: name,
+		next: next,
+	}
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if logging.IsDebugOrHigher() {
+		reqData, err := httputil.DumpRequestOut(req, true)
+		if err == nil {
+			log.Printf("[DEBUG] "+logReqMsg, t.name, redactDump(reqData))
+		} else {
+			log.Printf("[ERROR] %s API Request error: %#v", t.name, err)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if logging.IsDebugOrHigher() {
+		respData, err := httputil.DumpResponse(resp, true)
+		if err == nil {
+			log.Printf("[DEBUG] "+logRespMsg, t.name, redactDump(respData))
+		} else {
+			log.Printf("[ERROR] %s API Response error: %#v", t.name, err)
+		}
+	}
+
+	return resp, nil
+}
+
+// redactDump masks sensitive headers and JSON values in a dumped request or
+// response. JSON lines in the body are pretty printed.
+func redactDump(b []byte) string {
+	parts := strings.Split(string(b), "\n")
+	inHeader := true
+	for i, p := range parts {
+		if inHeader {
+			if strings.TrimSpace(p) == "" {
+				inHeader = false
+				continue
+			}
+			// the first line is the request or status line
+			if i > 0 {
+				parts[i] = redactHeaderLine(p)
+			}
+			continue
+		}
+		parts[i] = redactJSONLine(p)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func redactHeaderLine(line string) string {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return line
+	}
+	name := http.CanonicalHeaderKey(strings.TrimSpace(line[:i]))
+	if !sensitiveHeaders[name] {
+		return line
+	}
+
+	value := strings.TrimSpace(line[i+1:])
+	suffix := ""
+	if strings.HasSuffix(line, "\r") {
+		suffix = "\r"
+	}
+
+	switch name {
+	case "Cookie":
+		cookies := strings.Split(value, ";")
+		for j, c := range cookies {
+			cookies[j] = redactCookie(c)
+		}
+		value = strings.Join(cookies, ";")
+	case "Set-Cookie":
+		// only the first pair is the cookie, the rest are attributes
+		attrs := strings.SplitN(value, ";", 2)
+		attrs[0] = redactCookie(attrs[0])
+		value = strings.Join(attrs, ";")
+	default:
+		value = redactedValue
+	}
+
+	return line[:i] + ": " + value + suffix
+}
+
+func redactCookie(c string) string {
+	i := strings.Index(c, "=")
+	if i < 0 {
+		return c
+	}
+	return c[:i+1] + redactedValue
+}
+
+func redactJSONLine(line string) string {
+	b := []byte(line)
+	if !json.Valid(b) {
+		return line
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return line
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", " ")
+	if err := enc.Encode(redactJSON(v, false)); err != nil {
+		return line
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// redactJSON masks all non-empty strings below a sensitive key, booleans and
+// numbers are kept as they are useful for debugging and not secret.
+func redactJSON(v interface{}, sensitive bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = redactJSON(item, sensitive || isSensitiveKey(k))
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item, sensitive)
+		}
+		return v
+	case string:
+		if sensitive && v != "" {
+			return redactedValue
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isSensitiveKey(k string) bool {
+	k = strings.ToLower(k)
+	if strings.HasPrefix(k, "x_") {
+		return true
+	}
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(k, part) {
+			return true
+		}
+	}
+	return false
+}
+
+const logReqMsg = `%s API Request Details:
+---[ REQUEST ]---------------------------------------
+%s
+-----------------------------------------------------`
+
+const logRespMsg = `%s API Response Details:
+---[ RESPONSE ]--------------------------------------
+%s
+-----------------------------------------------------`
@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRedactJSONLine(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		line     string
+		expected string
+	}{
+		{
+			"login",
+			`{"username":"admin","password":"hunter2","remember":true}`,
+			`{
+ "password": "REDACTED",
+ "remember": true,
+ "username": "admin"
+}`,
+		},
+		{
+			"wlan",
+			`{"name":"tfacc","security":"wpapsk","x_passphrase":"12345678","wpa3_support":false}`,
+			`{
+ "name": "tfacc",
+ "security": "wpapsk",
+ "wpa3_support": false,
+ "x_passphrase": "REDACTED"
+}`,
+		},
+		{
+			"network wan",
+			`{"data":[{"name":"wan","wan_type":"pppoe","wan_username":"user","x_wan_password":"secret","wan_dhcp_options":[]}],"meta":{"rc":"ok"}}`,
+			`{
+ "data": [
+  {
+   "name": "wan",
+   "wan_dhcp_options": [],
+   "wan_type": "pppoe",
+   "wan_username": "user",
+   "x_wan_password": "REDACTED"
+  }
+ ],
+ "meta": {
+  "rc": "ok"
+ }
+}`,
+		},
+		{
+			"dynamic dns",
+			`{"service":"dyndns","host_name":"example.com","login":"user","x_password":"secret"}`,
+			`{
+ "host_name": "example.com",
+ "login": "user",
+ "service": "dyndns",
+ "x_password": "REDACTED"
+}`,
+		},
+		{
+			"radius profile",
+			`{"name":"radius","auth_servers":[{"ip":"192.168.1.1","port":1812,"x_secret":"secret"}]}`,
+			`{
+ "auth_servers": [
+  {
+   "ip": "192.168.1.1",
+   "port": 1812,
+   "x_secret": "REDACTED"
+  }
+ ],
+ "name": "radius"
+}`,
+		},
+		{
+			"nested sensitive",
+			`{"x_ssh_keys":[{"name":"key","key":"ssh-rsa AAAA"}],"x_ssh_enabled":true,"x_ssh_password":""}`,
+			`{
+ "x_ssh_enabled": true,
+ "x_ssh_keys": [
+  {
+   "key": "REDACTED",
+   "name": "REDACTED"
+  }
+ ],
+ "x_ssh_password": ""
+}`,
+		},
+		{
+			"large number",
+			`{"x_secret":"secret","time":1617181920212223}`,
+			`{
+ "time": 1617181920212223,
+ "x_secret": "REDACTED"
+}`,
+		},
+		{
+			"not json",
+			`password=hunter2`,
+			`password=hunter2`,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := redactJSONLine(c.line)
+			if actual != c.expected {
+				t.Fatalf("expected:\n%s\ngot:\n%s", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRedactHeaderLine(t *testing.T) {
+	for _, c := range []struct {
+		line     string
+		expected string
+	}{
+		{"Cookie: unifises=abc; csrf_token=def\r", "Cookie: unifises=REDACTED; csrf_token=REDACTED\r"},
+		{"Set-Cookie: unifises=abc; Path=/; Secure; HttpOnly\r", "Set-Cookie: unifises=REDACTED; Path=/; Secure; HttpOnly\r"},
+		{"X-Csrf-Token: abc\r", "X-Csrf-Token: REDACTED\r"},
+		{"x-api-key: abc", "x-api-key: REDACTED"},
+		{"Authorization: Bearer abc", "Authorization: REDACTED"},
+		{"Content-Type: application/json\r", "Content-Type: application/json\r"},
+	} {
+		t.Run(c.line, func(t *testing.T) {
+			actual := redactHeaderLine(c.line)
+			if actual != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestLoggingTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "unifises", Value: "session-cookie", Path: "/"})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"x_passphrase":"response-passphrase"}],"meta":{"rc":"ok"}}`))
+	}))
+	defer srv.Close()
+
+	oldLevel, hadLevel := os.LookupEnv("TF_LOG")
+	os.Setenv("TF_LOG", "DEBUG")
+	defer func() {
+		if hadLevel {
+			os.Setenv("TF_LOG", oldLevel)
+		} else {
+			os.Unsetenv("TF_LOG")
+		}
+	}()
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	hc := &http.Client{Transport: newLoggingTransport("Unifi", http.DefaultTransport)}
+	req, err := http.NewRequest("POST", srv.URL+"/api/login", strings.NewReader(`{"username":"admin","password":"request-password"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Cookie", "unifises=request-cookie")
+	resp, err := hc.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	logged := buf.String()
+	for _, secret := range []string{"request-password", "request-cookie", "session-cookie", "response-passphrase"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("expected %q to be redacted from the log:\n%s", secret, logged)
+		}
+	}
+	for _, expected := range []string{"Unifi API Request Details", "Unifi API Response Details", `"username": "admin"`} {
+		if !strings.Contains(logged, expected) {
+			t.Errorf("expected %q in the log:\n%s", expected, logged)
+		}
+	}
+}
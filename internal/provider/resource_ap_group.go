@@ -0,0 +1,208 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/paultyng/go-unifi/unifi"
+)
+
+func resourceAPGroup() *schema.Resource {
+	return &schema.Resource{
+		Description: "`unifi_ap_group` manages a group of access points, which can be used to limit which APs broadcast " +
+			"a WLAN. AP groups are only supported on controller version 6 and later.",
+
+		CreateContext: resourceAPGroupCreate,
+		ReadContext:   resourceAPGroupRead,
+		UpdateContext: resourceAPGroupUpdate,
+		DeleteContext: resourceAPGroupDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importSiteAndName("AP group", resourceAPGroupImportNames),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Description: "The ID of the AP group.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"site": {
+				Description: "The name of the site to associate the AP group with.",
+				Type:        schema.TypeString,
+				Computed:    true,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Description: "The name of the AP group.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"device_macs": {
+				Description: "MAC addresses of the access points in the group. Devices must already be adopted by the controller.",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateFunc:     validation.StringMatch(macAddressRegexp, "Mac address is invalid"),
+					DiffSuppressFunc: macDiffSuppressFunc,
+				},
+			},
+		},
+	}
+}
+
+func resourceAPGroupImportNames(ctx context.Context, c unifiClient, site string) ([]importName, error) {
+	groups, err := c.ListAPGroup(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]importName, 0, len(groups))
+	for _, o := range groups {
+		names = append(names, importName{id: o.ID, name: o.Name})
+	}
+	return names, nil
+}
+
+func resourceAPGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client)
+
+	v, err := c.ControllerVersion(ctx)
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	if err := checkCapabilities("unifi_ap_group", v, nil); err != nil {
+		return diag.FromErr(err)
+	}
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+
+	req, err := resourceAPGroupGetResourceData(ctx, d, c, site)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	resp, err := c.c.CreateAPGroup(ctx, site, req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(resp.ID)
+
+	return diag.FromErr(resourceAPGroupSetResourceData(resp, d, site))
+}
+
+func resourceAPGroupGetResourceData(ctx context.Context, d *schema.ResourceData, c *client, site string) (*unifi.APGroup, error) {
+	macs, err := setToStringSlice(d.Get("device_macs").(*schema.Set))
+	if err != nil {
+		return nil, err
+	}
+
+	deviceMACs := []string{}
+	if len(macs) > 0 {
+		devices, err := c.c.ListDevice(ctx, site)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list devices: %w", err)
+		}
+
+		known := map[string]string{}
+		for _, dev := range devices {
+			known[cleanMAC(dev.MAC)] = dev.MAC
+		}
+
+		for _, mac := range macs {
+			devMAC, ok := known[cleanMAC(mac)]
+			if !ok {
+				return nil, fmt.Errorf("device not found using mac %q", mac)
+			}
+			deviceMACs = append(deviceMACs, devMAC)
+		}
+	}
+
+	return &unifi.APGroup{
+		Name:       d.Get("name").(string),
+		DeviceMACs: deviceMACs,
+	}, nil
+}
+
+func resourceAPGroupSetResourceData(resp *unifi.APGroup, d *schema.ResourceData, site string) error {
+	d.Set("site", site)
+	d.Set("name", resp.Name)
+	d.Set("device_macs", stringSliceToSet(resp.DeviceMACs))
+
+	return nil
+}
+
+func resourceAPGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client)
+
+	id := d.Id()
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+
+	resp, err := c.c.GetAPGroup(ctx, site, id)
+	if _, ok := err.(*unifi.NotFoundError); ok {
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.FromErr(resourceAPGroupSetResourceData(resp, d, site))
+}
+
+func resourceAPGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client)
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+
+	req, err := resourceAPGroupGetResourceData(ctx, d, c, site)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	req.ID = d.Id()
+
+	resp, err := c.c.UpdateAPGroup(ctx, site, req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.FromErr(resourceAPGroupSetResourceData(resp, d, site))
+}
+
+func resourceAPGroupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client)
+
+	id := d.Id()
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+	err := c.c.DeleteAPGroup(ctx, site, id)
+	if _, ok := err.(*unifi.NotFoundError); ok {
+		return nil
+	}
+	return diag.FromErr(err)
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccAPGroup_basic(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			preCheck(t)
+			preCheckV6Only(t)
+		},
+		ProviderFactories: providerFactories,
+		// TODO: CheckDestroy: ,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAPGroupConfig("tfacc"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("unifi_ap_group.test", "name", "tfacc"),
+				),
+			},
+			importStep("unifi_ap_group.test"),
+			{
+				Config: testAccAPGroupConfig("tfacc-renamed"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("unifi_ap_group.test", "name", "tfacc-renamed"),
+				),
+			},
+			importStep("unifi_ap_group.test"),
+			{
+				ResourceName:      "unifi_ap_group.test",
+				ImportState:       true,
+				ImportStateId:     "default:name=tfacc-renamed",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccAPGroupConfig(name string) string {
+	return fmt.Sprintf(`
+resource "unifi_ap_group" "test" {
+	name = %q
+}
+`, name)
+}
+
+func TestAPGroup_fake(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	if _, err := s.AddObject("default", "device", map[string]interface{}{"mac": "00:00:5e:00:53:30", "type": "uap"}); err != nil {
+		t.Fatal(err)
+	}
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_ap_group",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"name": "tfacc",
+				},
+				importStateVerify: true,
+			},
+			{
+				config: map[string]interface{}{
+					"name":        "tfacc",
+					"device_macs": []interface{}{"00:00:5e:00:53:30"},
+				},
+				check:             fakeCheckAttr("device_macs.#", "1"),
+				importStateVerify: true,
+			},
+		},
+	})
+}
@@ -3,6 +3,7 @@
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -16,15 +17,24 @@ func resourceDevice() *schema.Resource {
 		Description: "`unifi_device` manages a device of the network.\n\n" +
 			"Devices are adopted by the controller, so it is not possible for this resource to be created through " +
 			"Terraform, the create operation instead will simply start managing the device specified by MAC address. " +
-			"It's safer to start this process with an explicit import of the device.",
+			"It's safer to start this process with an explicit import of the device. Set `adopt` to have the create " +
+			"operation adopt a device that is pending adoption first. Destroying the resource only removes the device " +
+			"from state, unless `forget_on_destroy` is set.",
 
-		Create:        resourceDeviceCreate,
-		Read:          resourceDeviceRead,
-		Update:        resourceDeviceUpdate,
+		CreateContext: resourceDeviceCreate,
+		ReadContext:   resourceDeviceRead,
+		UpdateContext: resourceDeviceUpdate,
 		DeleteContext: resourceDeviceDelete,
+		CustomizeDiff: resourceDeviceCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceDeviceImport,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"id": {
@@ -54,10 +64,33 @@ func resourceDevice() *schema.Resource {
 				Optional:    true,
 				Computed:    true,
 			},
-			"disabled": {
-				Description: "Specifies whether this device should be disabled.",
+			"adopt": {
+				Description: "Specifies whether to adopt the device if it is pending adoption when the resource is " +
+					"created. The create operation waits for the device to connect before applying its settings, the " +
+					"`create` timeout may need to be raised for slow adoptions.",
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"forget_on_destroy": {
+				Description: "Specifies whether to forget the device on the controller when the resource is destroyed. " +
+					"Devices that are online are only forgotten if `force` is set.",
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"force": {
+				Description: "Specifies whether `forget_on_destroy` should also forget a device that is online.",
 				Type:        schema.TypeBool,
-				Computed:    true,
+				Optional:    true,
+				Default:     false,
+			},
+			"disabled": {
+				Description: "Specifies whether this device should be disabled. The device is left as it is if this " +
+					"is not set.",
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
 			},
 			"port_override": {
 				Description: "Settings overrides for specific switch ports.",
@@ -82,6 +115,129 @@ func resourceDevice() *schema.Resource {
 							Type:        schema.TypeString,
 							Optional:    true,
 						},
+						"op_mode": {
+							Description: "Operating mode of the port, the only valid value is `switch`. Use the " +
+								"`aggregate` and `mirror` blocks for the other modes.",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice([]string{"switch"}, false),
+						},
+						"poe_mode": {
+							Description:  "PoE mode of the port, valid values are `auto`, `pasv24`, `passthrough` and `off`.",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice([]string{"auto", "pasv24", "passthrough", "off"}, false),
+						},
+						"native_network_id": {
+							Description: "ID of the native (untagged) network of the port.",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"tagged_network_ids": {
+							Description: "IDs of the tagged networks of the port.",
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"speed": {
+							Description:  "Fixed link speed of the port in Mbps, setting it disables autonegotiation.",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntInSlice([]int{10, 100, 1000, 2500, 5000, 10000, 20000, 25000, 40000, 50000, 100000}),
+						},
+						"full_duplex": {
+							Description: "Whether the link is full duplex (only valid with `speed`).",
+							Type:        schema.TypeBool,
+							Optional:    true,
+						},
+						"storm_control_broadcast_rate": {
+							Description:  "Broadcast storm control limit in packets per second, `0` disables it.",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntBetween(0, 14880000),
+						},
+						"storm_control_multicast_rate": {
+							Description:  "Multicast storm control limit in packets per second, `0` disables it.",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntBetween(0, 14880000),
+						},
+						"storm_control_unicast_rate": {
+							Description:  "Unknown unicast storm control limit in packets per second, `0` disables it.",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntBetween(0, 14880000),
+						},
+						"stp_disabled": {
+							Description: "Whether spanning tree is disabled on the port.",
+							Type:        schema.TypeBool,
+							Optional:    true,
+						},
+						"lldpmed_disabled": {
+							Description: "Whether LLDP-MED is disabled on the port.",
+							Type:        schema.TypeBool,
+							Optional:    true,
+						},
+						"lldpmed_notify_enabled": {
+							Description: "Whether LLDP-MED topology change notifications are enabled on the port.",
+							Type:        schema.TypeBool,
+							Optional:    true,
+						},
+						"isolation": {
+							Description: "Whether the port is isolated, so it can only talk to the uplink.",
+							Type:        schema.TypeBool,
+							Optional:    true,
+						},
+						"egress_rate_limit_kbps": {
+							Description:  "Egress rate limit of the port in kbps, `0` disables it.",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.Any(validation.IntInSlice([]int{0}), validation.IntBetween(64, 9999999)),
+						},
+					},
+				},
+			},
+			"aggregate": {
+				Description: "Link aggregations (LACP) of switch ports. The members of an aggregation are consecutive " +
+					"ports, starting at `port`, and cannot have a `port_override`. Changes to the ports of the device's uplink are applied after all " +
+					"other port changes.",
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"port": {
+							Description: "First port of the aggregation.",
+							Type:        schema.TypeInt,
+							Required:    true,
+						},
+						"num_ports": {
+							Description:  "Number of member ports, at least `2`.",
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntAtLeast(2),
+						},
+					},
+				},
+			},
+			"mirror": {
+				Description: "Port mirroring of switch ports. The destination port cannot have a `port_override`, and " +
+					"the uplink port of the device cannot mirror another port.",
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"port": {
+							Description: "Port the mirrored traffic is sent to.",
+							Type:        schema.TypeInt,
+							Required:    true,
+						},
+						"source_port": {
+							Description: "Port whose traffic is mirrored.",
+							Type:        schema.TypeInt,
+							Required:    true,
+						},
 					},
 				},
 			},
@@ -130,11 +286,15 @@ func resourceDeviceImport(ctx context.Context, d *schema.ResourceData, meta inte
 	if site != "" {
 		d.Set("site", site)
 	}
+	// these only apply when creating or destroying, so use their defaults
+	d.Set("adopt", false)
+	d.Set("forget_on_destroy", false)
+	d.Set("force", false)
 
 	return []*schema.ResourceData{d}, nil
 }
 
-func resourceDeviceCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceDeviceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	site := d.Get("site").(string)
@@ -144,13 +304,23 @@ func resourceDeviceCreate(d *schema.ResourceData, meta interface{}) error {
 
 	mac := d.Get("mac").(string)
 	if mac == "" {
-		return fmt.Errorf("no MAC address specified, please import the device using terraform import")
+		return diag.Errorf("no MAC address specified, please import the device using terraform import")
 	}
 
 	mac = cleanMAC(mac)
-	devices, err := c.c.ListDevice(context.TODO(), site)
+
+	adopted := false
+	if d.Get("adopt").(bool) {
+		var err error
+		adopted, err = resourceDeviceAdopt(ctx, c, site, mac)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	devices, err := c.c.ListDevice(ctx, site)
 	if err != nil {
-		return fmt.Errorf("unable to list devices: %w", err)
+		return diag.FromErr(fmt.Errorf("unable to list devices: %w", err))
 	}
 
 	var found *unifi.Device
@@ -161,15 +331,49 @@ func resourceDeviceCreate(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 	if found == nil {
-		return fmt.Errorf("device not found using mac %q", mac)
+		return diag.Errorf("device not found using mac %q", mac)
 	}
 
 	d.SetId(found.ID)
 
-	return resourceDeviceSetResourceData(found, d, site)
+	if adopted {
+		// a newly adopted device has no settings of its own yet
+		return resourceDeviceUpdate(ctx, d, meta)
+	}
+
+	overrides, err := c.c.GetDevicePortOverrides(ctx, site, found.ID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.FromErr(resourceDeviceSetResourceData(found, overrides, d, site))
+}
+
+// resourceDeviceAdopt adopts the device if it is pending adoption and waits
+// for it to connect. It reports whether the device was adopted.
+func resourceDeviceAdopt(ctx context.Context, c *client, site, mac string) (bool, error) {
+	dev, err := findDeviceStatus(ctx, c.c, site, mac)
+	if err != nil {
+		return false, fmt.Errorf("unable to list devices: %w", err)
+	}
+	if dev == nil {
+		return false, fmt.Errorf("device not found using mac %q", mac)
+	}
+	if dev.State != deviceStatePendingAdoption {
+		return false, nil
+	}
+
+	if err := c.c.AdoptDevice(ctx, site, mac); err != nil {
+		return false, fmt.Errorf("unable to adopt device %q: %w", mac, err)
+	}
+	if err := waitForDeviceState(ctx, c.c, site, mac, deviceStateConnected); err != nil {
+		return false, err
+	}
+
+	return true, nil
 }
 
-func resourceDeviceUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceDeviceUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	site := d.Get("site").(string)
@@ -179,30 +383,78 @@ func resourceDeviceUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	req, err := resourceDeviceGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
+	}
+
+	overrides, err := resourceDeviceGetPortOverrides(d)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
 	req.ID = d.Id()
 	req.SiteID = site
 
-	resp, err := c.c.UpdateDevice(context.TODO(), site, req)
+	// enable the device before applying settings, and only disable it after
+	disabled := d.Get("disabled").(bool)
+	if d.HasChange("disabled") && !disabled {
+		if err := c.c.SetDeviceDisabled(ctx, site, req.ID, false); err != nil {
+			return diag.FromErr(fmt.Errorf("unable to enable device: %w", err))
+		}
+	}
+
+	resp, err := c.c.UpdateDevice(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
+	}
+
+	overrides, err = resourceDeviceUpdatePortOverrides(ctx, c, site, req.ID, d.Get("mac").(string), overrides)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("unable to update port overrides: %w", err))
+	}
+
+	if d.HasChange("disabled") && disabled {
+		if err := c.c.SetDeviceDisabled(ctx, site, req.ID, true); err != nil {
+			return diag.FromErr(fmt.Errorf("unable to disable device: %w", err))
+		}
+		resp.Disabled = true
 	}
 
-	return resourceDeviceSetResourceData(resp, d, site)
+	return diag.FromErr(resourceDeviceSetResourceData(resp, overrides, d, site))
 }
 
-func resourceDeviceDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	return diag.Diagnostics{
-		diag.Diagnostic{
-			Severity: diag.Warning,
-			Summary:  "Deleting a device via Terraform is not supported, the device will just be removed from state.",
-		},
+func resourceDeviceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if !d.Get("forget_on_destroy").(bool) {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Deleting a device via Terraform is not supported, the device will just be removed from state.",
+			},
+		}
 	}
+
+	c := meta.(*client)
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+	mac := d.Get("mac").(string)
+
+	dev, err := findDeviceStatus(ctx, c.c, site, mac)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("unable to list devices: %w", err))
+	}
+	if dev == nil {
+		return nil
+	}
+	if dev.State != deviceStateDisconnected && !d.Get("force").(bool) {
+		return diag.Errorf("device %q is %s, set force to forget a device that is online", mac, deviceStateName(dev.State))
+	}
+
+	return diag.FromErr(c.c.ForgetDevice(ctx, site, mac))
 }
 
-func resourceDeviceRead(d *schema.ResourceData, meta interface{}) error {
+func resourceDeviceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -212,20 +464,31 @@ func resourceDeviceRead(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	resp, err := c.c.GetDevice(context.TODO(), site, id)
+	resp, err := c.c.GetDevice(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		d.SetId("")
 		return nil
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceDeviceSetResourceData(resp, d, site)
+	overrides, err := c.c.GetDevicePortOverrides(ctx, site, id)
+	if _, ok := err.(*unifi.NotFoundError); ok {
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.FromErr(resourceDeviceSetResourceData(resp, overrides, d, site))
 }
 
-func resourceDeviceSetResourceData(resp *unifi.Device, d *schema.ResourceData, site string) error {
-	portOverrides, err := setFromPortOverrides(resp.PortOverrides)
+func resourceDeviceSetResourceData(resp *unifi.Device, overrides []devicePortOverride, d *schema.ResourceData, site string) error {
+	overrides, aggregates, mirrors := splitPortOverrides(overrides)
+
+	portOverrides, err := setFromPortOverrides(overrides)
 	if err != nil {
 		return err
 	}
@@ -235,28 +498,126 @@ func resourceDeviceSetResourceData(resp *unifi.Device, d *schema.ResourceData, s
 	d.Set("name", resp.Name)
 	d.Set("disabled", resp.Disabled)
 	d.Set("port_override", portOverrides)
+	d.Set("aggregate", aggregates)
+	d.Set("mirror", mirrors)
 
 	return nil
 }
 
 func resourceDeviceGetResourceData(d *schema.ResourceData) (*unifi.Device, error) {
-	pos, err := setToPortOverrides(d.Get("port_override").(*schema.Set))
+	// disabled and the port overrides are changed separately, see
+	// resourceDeviceUpdate
+
+	return &unifi.Device{
+		MAC:  d.Get("mac").(string),
+		Name: d.Get("name").(string),
+	}, nil
+}
+
+// resourceDeviceGetPortOverrides returns the port overrides with the overrides
+// of the aggregate and mirror blocks added, their ports cannot have a
+// port_override, see resourceDeviceValidatePortModes.
+func resourceDeviceGetPortOverrides(d *schema.ResourceData) ([]devicePortOverride, error) {
+	overrides, err := setToPortOverrides(d.Get("port_override").(*schema.Set))
 	if err != nil {
 		return nil, fmt.Errorf("unable to process port_override block: %w", err)
 	}
 
-	//TODO: pass Disabled once we figure out how to enable the device afterwards
+	for _, raw := range d.Get("aggregate").(*schema.Set).List() {
+		data := raw.(map[string]interface{})
+		overrides = append(overrides, devicePortOverride{
+			PortIDX:           data["port"].(int),
+			OpMode:            "aggregate",
+			AggregateNumPorts: data["num_ports"].(int),
+		})
+	}
+	for _, raw := range d.Get("mirror").(*schema.Set).List() {
+		data := raw.(map[string]interface{})
+		overrides = append(overrides, devicePortOverride{
+			PortIDX:       data["port"].(int),
+			OpMode:        "mirror",
+			MirrorPortIDX: data["source_port"].(int),
+		})
+	}
+
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].PortIDX < overrides[j].PortIDX })
+	return overrides, nil
+}
 
-	return &unifi.Device{
-		MAC:           d.Get("mac").(string),
-		Name:          d.Get("name").(string),
-		PortOverrides: pos,
-	}, nil
+// splitPortOverrides separates the overrides of aggregate and mirror ports
+// from the others.
+func splitPortOverrides(overrides []devicePortOverride) ([]devicePortOverride, []map[string]interface{}, []map[string]interface{}) {
+	pos := make([]devicePortOverride, 0, len(overrides))
+	aggregates := []map[string]interface{}{}
+	mirrors := []map[string]interface{}{}
+	for _, po := range overrides {
+		switch po.OpMode {
+		case "aggregate":
+			aggregates = append(aggregates, map[string]interface{}{
+				"port":      po.PortIDX,
+				"num_ports": po.AggregateNumPorts,
+			})
+		case "mirror":
+			mirrors = append(mirrors, map[string]interface{}{
+				"port":        po.PortIDX,
+				"source_port": po.MirrorPortIDX,
+			})
+		default:
+			pos = append(pos, po)
+		}
+	}
+	return pos, aggregates, mirrors
 }
 
-func setToPortOverrides(set *schema.Set) ([]unifi.DevicePortOverrides, error) {
+// resourceDeviceUpdatePortOverrides applies the port overrides. If the ports
+// of the uplink change, every other change is applied first and provisioned
+// while the device is reachable, and then the uplink changes are applied and
+// the device is given time to reconnect.
+func resourceDeviceUpdatePortOverrides(ctx context.Context, c *client, site, id, mac string, overrides []devicePortOverride) ([]devicePortOverride, error) {
+	dev, err := findDeviceStatus(ctx, c.c, site, mac)
+	if err != nil {
+		return nil, err
+	}
+	if dev == nil || deviceUplinkPort(dev) == 0 {
+		return c.c.UpdateDevicePortOverrides(ctx, site, id, overrides)
+	}
+
+	current, err := c.c.GetDevicePortOverrides(ctx, site, id)
+	if err != nil {
+		return nil, err
+	}
+	interim, ok := uplinkInterimPortOverrides(deviceUplinkPort(dev), current, overrides)
+	if !ok {
+		return c.c.UpdateDevicePortOverrides(ctx, site, id, overrides)
+	}
+
+	// a device that is not connected cannot report when it applied a change
+	connected := dev.State == deviceStateConnected
+
+	if _, err := c.c.UpdateDevicePortOverrides(ctx, site, id, interim); err != nil {
+		return nil, err
+	}
+	cfgVersion := dev.CfgVersion
+	if connected {
+		if cfgVersion, err = waitForDeviceReconnect(ctx, c.c, site, mac, cfgVersion); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := c.c.UpdateDevicePortOverrides(ctx, site, id, overrides)
+	if err != nil {
+		return nil, err
+	}
+	if connected {
+		if _, err := waitForDeviceReconnect(ctx, c.c, site, mac, cfgVersion); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+func setToPortOverrides(set *schema.Set) ([]devicePortOverride, error) {
 	// use a map here to remove any duplication
-	overrideMap := map[int]unifi.DevicePortOverrides{}
+	overrideMap := map[int]devicePortOverride{}
 	for _, item := range set.List() {
 		data, ok := item.(map[string]interface{})
 		if !ok {
@@ -269,14 +630,15 @@ func setToPortOverrides(set *schema.Set) ([]unifi.DevicePortOverrides, error) {
 		overrideMap[po.PortIDX] = po
 	}
 
-	pos := make([]unifi.DevicePortOverrides, 0, len(overrideMap))
+	pos := make([]devicePortOverride, 0, len(overrideMap))
 	for _, item := range overrideMap {
 		pos = append(pos, item)
 	}
+	sort.Slice(pos, func(i, j int) bool { return pos[i].PortIDX < pos[j].PortIDX })
 	return pos, nil
 }
 
-func setFromPortOverrides(pos []unifi.DevicePortOverrides) ([]map[string]interface{}, error) {
+func setFromPortOverrides(pos []devicePortOverride) ([]map[string]interface{}, error) {
 	list := make([]map[string]interface{}, 0, len(pos))
 	for _, po := range pos {
 		v, err := fromPortOverride(po)
@@ -288,22 +650,217 @@ func setFromPortOverrides(pos []unifi.DevicePortOverrides) ([]map[string]interfa
 	return list, nil
 }
 
-func toPortOverride(data map[string]interface{}) (unifi.DevicePortOverrides, error) {
-	// TODO: error check these?
-	idx := data["number"].(int)
-	name := data["name"].(string)
-	profile_id := data["port_profile_id"].(string)
-	return unifi.DevicePortOverrides{
-		PortIDX:       idx,
-		Name:          name,
-		PortProfileID: profile_id,
-	}, nil
+func toPortOverride(data map[string]interface{}) (devicePortOverride, error) {
+	tagged, err := setToStringSlice(data["tagged_network_ids"].(*schema.Set))
+	if err != nil {
+		return devicePortOverride{}, err
+	}
+
+	bcast := data["storm_control_broadcast_rate"].(int)
+	mcast := data["storm_control_multicast_rate"].(int)
+	ucast := data["storm_control_unicast_rate"].(int)
+	egress := data["egress_rate_limit_kbps"].(int)
+
+	po := devicePortOverride{
+		PortIDX:          data["number"].(int),
+		Name:             data["name"].(string),
+		PortProfileID:    data["port_profile_id"].(string),
+		OpMode:           data["op_mode"].(string),
+		PoeMode:          data["poe_mode"].(string),
+		NativeNetworkID:  data["native_network_id"].(string),
+		TaggedNetworkIDs: tagged,
+
+		StormctrlBcastEnabled:  bcast != 0,
+		StormctrlBcastRate:     bcast,
+		StormctrlMcastEnabled:  mcast != 0,
+		StormctrlMcastRate:     mcast,
+		StormctrlUcastEnabled:  ucast != 0,
+		StormctrlUcastRate:     ucast,
+		LldpmedNotifyEnabled:   data["lldpmed_notify_enabled"].(bool),
+		Isolation:              data["isolation"].(bool),
+		EgressRateLimitEnabled: egress != 0,
+		EgressRateLimitKbps:    egress,
+	}
+	// settings that default to on are only sent when they are turned off
+	off := false
+	if speed := data["speed"].(int); speed != 0 {
+		po.Autoneg = &off
+		po.Speed = speed
+		po.FullDuplex = data["full_duplex"].(bool)
+	}
+	if data["stp_disabled"].(bool) {
+		po.StpPortMode = &off
+	}
+	if data["lldpmed_disabled"].(bool) {
+		po.LldpmedEnabled = &off
+	}
+	if bcast != 0 || mcast != 0 || ucast != 0 {
+		po.StormctrlType = "rate"
+	}
+	return po, nil
 }
 
-func fromPortOverride(po unifi.DevicePortOverrides) (map[string]interface{}, error) {
+func fromPortOverride(po devicePortOverride) (map[string]interface{}, error) {
+	// missing settings that default to on are on
+	speed, fullDuplex := 0, false
+	if po.Autoneg != nil && !*po.Autoneg {
+		speed, fullDuplex = po.Speed, po.FullDuplex
+	}
+
+	bcast, mcast, ucast := 0, 0, 0
+	if po.StormctrlBcastEnabled {
+		bcast = po.StormctrlBcastRate
+	}
+	if po.StormctrlMcastEnabled {
+		mcast = po.StormctrlMcastRate
+	}
+	if po.StormctrlUcastEnabled {
+		ucast = po.StormctrlUcastRate
+	}
+	egress := 0
+	if po.EgressRateLimitEnabled {
+		egress = po.EgressRateLimitKbps
+	}
+
 	return map[string]interface{}{
-		"number":          po.PortIDX,
-		"name":            po.Name,
-		"port_profile_id": po.PortProfileID,
+		"number":                       po.PortIDX,
+		"name":                         po.Name,
+		"port_profile_id":              po.PortProfileID,
+		"op_mode":                      po.OpMode,
+		"poe_mode":                     po.PoeMode,
+		"native_network_id":            po.NativeNetworkID,
+		"tagged_network_ids":           stringSliceToSet(po.TaggedNetworkIDs),
+		"speed":                        speed,
+		"full_duplex":                  fullDuplex,
+		"storm_control_broadcast_rate": bcast,
+		"storm_control_multicast_rate": mcast,
+		"storm_control_unicast_rate":   ucast,
+		"stp_disabled":                 po.StpPortMode != nil && !*po.StpPortMode,
+		"lldpmed_disabled":             po.LldpmedEnabled != nil && !*po.LldpmedEnabled,
+		"lldpmed_notify_enabled":       po.LldpmedNotifyEnabled,
+		"isolation":                    po.Isolation,
+		"egress_rate_limit_kbps":       egress,
 	}, nil
 }
+
+func resourceDeviceCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("port_override") {
+		return nil
+	}
+
+	for _, raw := range diff.Get("port_override").(*schema.Set).List() {
+		po := raw.(map[string]interface{})
+		number := po["number"].(int)
+		if po["full_duplex"].(bool) && po["speed"].(int) == 0 {
+			return fmt.Errorf("port_override %d: full_duplex requires speed", number)
+		}
+	}
+
+	return resourceDeviceValidatePortModes(ctx, diff, meta)
+}
+
+// resourceDeviceValidatePortModes checks that the ports of the aggregate and
+// mirror blocks do not overlap, and that they exist on the device if it is
+// known.
+func resourceDeviceValidatePortModes(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("aggregate") || !diff.NewValueKnown("mirror") {
+		return nil
+	}
+	aggregates := diff.Get("aggregate").(*schema.Set).List()
+	mirrors := diff.Get("mirror").(*schema.Set).List()
+	if len(aggregates) == 0 && len(mirrors) == 0 {
+		return nil
+	}
+
+	used := map[int]string{}
+	use := func(port int, by string) error {
+		if other, ok := used[port]; ok {
+			return fmt.Errorf("port %d is used by both %s and %s", port, other, by)
+		}
+		used[port] = by
+		return nil
+	}
+	ports := []int{}
+	for _, raw := range aggregates {
+		data := raw.(map[string]interface{})
+		first := data["port"].(int)
+		for p := first; p < first+data["num_ports"].(int); p++ {
+			if err := use(p, fmt.Sprintf("aggregate %d", first)); err != nil {
+				return err
+			}
+			ports = append(ports, p)
+		}
+	}
+	sources := []int{}
+	for _, raw := range mirrors {
+		data := raw.(map[string]interface{})
+		port, source := data["port"].(int), data["source_port"].(int)
+		if port == source {
+			return fmt.Errorf("mirror %d cannot mirror itself", port)
+		}
+		if err := use(port, fmt.Sprintf("mirror %d", port)); err != nil {
+			return err
+		}
+		ports = append(ports, port)
+		sources = append(sources, source)
+	}
+	for _, source := range sources {
+		if by := used[source]; strings.HasPrefix(by, "mirror ") {
+			return fmt.Errorf("port %d cannot be mirrored as it is used by %s", source, by)
+		}
+	}
+
+	// the blocks set the op_mode of their ports
+	if diff.NewValueKnown("port_override") {
+		for _, raw := range diff.Get("port_override").(*schema.Set).List() {
+			number := raw.(map[string]interface{})["number"].(int)
+			if by, ok := used[number]; ok {
+				return fmt.Errorf("port_override %d: the port is used by %s", number, by)
+			}
+		}
+	}
+
+	c, ok := meta.(*client)
+	if !ok {
+		return nil
+	}
+	mac := diff.Get("mac").(string)
+	if mac == "" {
+		return nil
+	}
+	site := diff.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+	dev, err := findDeviceStatus(ctx, c.c, site, mac)
+	if err != nil {
+		return err
+	}
+	if dev == nil {
+		// create reports the missing device
+		return nil
+	}
+
+	exists := map[int]bool{}
+	for _, p := range dev.PortTable {
+		exists[p.PortIDX] = true
+	}
+	for _, raw := range aggregates {
+		data := raw.(map[string]interface{})
+		first, n := data["port"].(int), data["num_ports"].(int)
+		if !exists[first+n-1] {
+			return fmt.Errorf("aggregate %d: device %q does not have %d ports from port %d", first, mac, n, first)
+		}
+	}
+	for _, p := range append(ports, sources...) {
+		if !exists[p] {
+			return fmt.Errorf("port %d does not exist on device %q", p, mac)
+		}
+	}
+
+	if uplink := deviceUplinkPort(dev); uplink != 0 && strings.HasPrefix(used[uplink], "mirror ") {
+		return fmt.Errorf("port %d is the uplink of device %q, it cannot be a mirror destination", uplink, mac)
+	}
+
+	return nil
+}
@@ -1,13 +1,17 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func preCheckSwitch(t *testing.T) {
@@ -67,7 +71,23 @@ func TestAccDevice_switch_basic(t *testing.T) {
 				ResourceName:      "unifi_device.test",
 			},
 
-			// TODO: update switch
+			{
+				Config: testAccDeviceConfigDisabled(switchMAC, true),
+				Check:  resource.TestCheckResourceAttr("unifi_device.test", "disabled", "true"),
+			},
+			{
+				Config:   testAccDeviceConfigDisabled(switchMAC, true),
+				PlanOnly: true,
+			},
+			{
+				Config: testAccDeviceConfigDisabled(switchMAC, false),
+				Check:  resource.TestCheckResourceAttr("unifi_device.test", "disabled", "false"),
+			},
+			{
+				Config:   testAccDeviceConfigDisabled(switchMAC, false),
+				PlanOnly: true,
+			},
+
 			// TODO: test port overrides
 		},
 	})
@@ -87,3 +107,718 @@ func testAccDeviceConfig(mac string) string {
 }
 `, mac)
 }
+
+func testAccDeviceConfigDisabled(mac string, disabled bool) string {
+	return fmt.Sprintf(`
+resource "unifi_device" "test" {
+	mac      = %q
+	disabled = %t
+}
+`, mac, disabled)
+}
+
+func TestDevice_fake(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	id, err := s.AddObject("default", "device", map[string]interface{}{
+		"mac":  "00:00:5e:00:53:20",
+		"name": "switch",
+		"type": "usw",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_device",
+		steps: []fakeStep{
+			{
+				// create only starts managing the device
+				config: map[string]interface{}{
+					"mac": "00:00:5e:00:53:20",
+				},
+				check:             fakeCheckAttr("id", id),
+				importStateVerify: true,
+			},
+			{
+				config: map[string]interface{}{
+					"mac":  "00:00:5e:00:53:20",
+					"name": "tfacc",
+					"port_override": []interface{}{
+						map[string]interface{}{
+							"number": 1,
+							"name":   "uplink",
+						},
+					},
+				},
+				check:             fakeCheckAttr("port_override.#", "1"),
+				importStateVerify: true,
+			},
+			{
+				config: map[string]interface{}{
+					"mac":      "00:00:5e:00:53:20",
+					"name":     "tfacc",
+					"disabled": true,
+					"port_override": []interface{}{
+						map[string]interface{}{
+							"number": 1,
+							"name":   "uplink",
+						},
+					},
+				},
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					fakeCheckAttr("disabled", "true")(t, state)
+					if disabled := s.Object("default", "device", id)["disabled"]; disabled != true {
+						t.Errorf("expected the device to be disabled, got %v", disabled)
+					}
+				},
+				importStateVerify: true,
+			},
+			{
+				config: map[string]interface{}{
+					"mac":      "00:00:5e:00:53:20",
+					"name":     "tfacc",
+					"disabled": false,
+					"port_override": []interface{}{
+						map[string]interface{}{
+							"number": 1,
+							"name":   "uplink",
+						},
+					},
+				},
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					fakeCheckAttr("disabled", "false")(t, state)
+					if disabled := s.Object("default", "device", id)["disabled"]; disabled != false {
+						t.Errorf("expected the device to be enabled, got %v", disabled)
+					}
+				},
+			},
+		},
+		// devices are only removed from state
+		skipCheckDestroy: true,
+	})
+}
+
+func TestDevice_fake_adopt(t *testing.T) {
+	defer func(interval time.Duration) { devicePollInterval = interval }(devicePollInterval)
+	devicePollInterval = time.Millisecond
+
+	c, s := newFakeClient(t)
+
+	id, err := s.AddObject("default", "device", map[string]interface{}{
+		"mac":     "00:00:5e:00:53:21",
+		"type":    "usw",
+		"adopted": false,
+		"state":   2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_device",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"mac":   "00:00:5e:00:53:21",
+					"name":  "tfacc",
+					"adopt": true,
+					"port_override": []interface{}{
+						map[string]interface{}{
+							"number": 1,
+							"name":   "uplink",
+						},
+					},
+				},
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					fakeCheckAttr("id", id)(t, state)
+					fakeCheckAttr("name", "tfacc")(t, state)
+					fakeCheckAttr("port_override.#", "1")(t, state)
+
+					dev := s.Object("default", "device", id)
+					if dev["adopted"] != true || fmt.Sprint(dev["state"]) != "1" {
+						t.Errorf("expected the device to be adopted and connected, got %v", dev)
+					}
+				},
+				importStateVerify:       true,
+				importStateVerifyIgnore: []string{"adopt"},
+			},
+		},
+		// devices are only removed from state
+		skipCheckDestroy: true,
+	})
+}
+
+func TestDevice_fake_forgetOnDestroy(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	id, err := s.AddObject("default", "device", map[string]interface{}{
+		"mac":     "00:00:5e:00:53:22",
+		"type":    "uap",
+		"adopted": true,
+		"state":   0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the offline device is forgotten without force
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_device",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"mac":               "00:00:5e:00:53:22",
+					"forget_on_destroy": true,
+				},
+				check: fakeCheckAttr("id", id),
+			},
+		},
+	})
+	if len(s.Objects("default", "device")) != 0 {
+		t.Fatal("expected the device to be forgotten")
+	}
+
+	id, err = s.AddObject("default", "device", map[string]interface{}{
+		"mac":     "00:00:5e:00:53:23",
+		"type":    "uap",
+		"adopted": true,
+		"state":   1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := New("test")().ResourcesMap["unifi_device"]
+	destroy := func(force bool) diag.Diagnostics {
+		state := &terraform.InstanceState{
+			ID: id,
+			Attributes: map[string]string{
+				"id":                id,
+				"mac":               "00:00:5e:00:53:23",
+				"forget_on_destroy": "true",
+				"force":             fmt.Sprint(force),
+			},
+		}
+		_, diags := r.Apply(context.Background(), state, &terraform.InstanceDiff{Destroy: true}, c)
+		return diags
+	}
+
+	diags := destroy(false)
+	if !diags.HasError() || !strings.Contains(diagsString(diags), "set force to forget a device that is online") {
+		t.Fatalf("expected the online device to be refused, got %q", diagsString(diags))
+	}
+	if s.Object("default", "device", id) == nil {
+		t.Fatal("expected the online device to be kept")
+	}
+
+	if diags := destroy(true); diags.HasError() {
+		t.Fatal(diagsString(diags))
+	}
+	if s.Object("default", "device", id) != nil {
+		t.Fatal("expected the online device to be forgotten with force")
+	}
+}
+
+func TestDevice_fake_portOverride(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	id, err := s.AddObject("default", "device", map[string]interface{}{
+		"mac":  "00:00:5e:00:53:24",
+		"name": "switch",
+		"type": "usw",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nativeID, err := s.AddObject("default", "networkconf", map[string]interface{}{"name": "native", "purpose": "corporate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	taggedID, err := s.AddObject("default", "networkconf", map[string]interface{}{"name": "tagged", "purpose": "vlan-only"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_device",
+		steps: []fakeStep{
+			{
+				// create only starts managing the device
+				config: map[string]interface{}{
+					"mac": "00:00:5e:00:53:24",
+				},
+			},
+			{
+				config: map[string]interface{}{
+					"mac": "00:00:5e:00:53:24",
+					"port_override": []interface{}{
+						map[string]interface{}{
+							"number":                       1,
+							"name":                         "camera",
+							"op_mode":                      "switch",
+							"poe_mode":                     "off",
+							"native_network_id":            nativeID,
+							"tagged_network_ids":           []interface{}{taggedID},
+							"speed":                        100,
+							"full_duplex":                  true,
+							"storm_control_broadcast_rate": 1000,
+							"stp_disabled":                 true,
+							"lldpmed_disabled":             true,
+							"isolation":                    true,
+							"egress_rate_limit_kbps":       10000,
+						},
+						map[string]interface{}{
+							"number": 2,
+							"name":   "uplink",
+						},
+					},
+				},
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					fakeCheckAttr("port_override.#", "2")(t, state)
+
+					overrides := s.Object("default", "device", id)["port_overrides"].([]interface{})
+					if len(overrides) != 2 {
+						t.Fatalf("expected 2 port overrides, got %d", len(overrides))
+					}
+					po := overrides[0].(map[string]interface{})
+					for k, v := range map[string]interface{}{
+						"port_idx":                       float64(1),
+						"poe_mode":                       "off",
+						"native_networkconf_id":          nativeID,
+						"autoneg":                        false,
+						"speed":                          float64(100),
+						"full_duplex":                    true,
+						"stormctrl_type":                 "rate",
+						"stormctrl_bcast_enabled":        true,
+						"stormctrl_bcast_rate":           float64(1000),
+						"stp_port_mode":                  false,
+						"lldpmed_enabled":                false,
+						"isolation":                      true,
+						"egress_rate_limit_kbps_enabled": true,
+						"egress_rate_limit_kbps":         float64(10000),
+					} {
+						if po[k] != v {
+							t.Errorf("expected %s = %v, got %v", k, v, po[k])
+						}
+					}
+
+					// settings that are not configured are left to the port profile
+					if po := overrides[1].(map[string]interface{}); len(po) != 2 {
+						t.Errorf("expected only port_idx and name to be sent, got %v", po)
+					}
+				},
+				importStateVerify: true,
+			},
+			{
+				// removed overrides are cleared on the controller
+				config: map[string]interface{}{
+					"mac": "00:00:5e:00:53:24",
+				},
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					fakeCheckAttr("port_override.#", "0")(t, state)
+					if overrides := s.Object("default", "device", id)["port_overrides"].([]interface{}); len(overrides) != 0 {
+						t.Errorf("expected the port overrides to be cleared, got %v", overrides)
+					}
+				},
+			},
+		},
+		// devices are only removed from state
+		skipCheckDestroy: true,
+	})
+
+	err = testFakePlanError(t, c, "unifi_device", map[string]interface{}{
+		"mac": "00:00:5e:00:53:24",
+		"port_override": []interface{}{
+			map[string]interface{}{
+				"number":      1,
+				"full_duplex": true,
+			},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "full_duplex requires speed") {
+		t.Fatalf("expected a full_duplex error, got %v", err)
+	}
+}
+
+func TestUplinkInterimPortOverrides(t *testing.T) {
+	aggregate := func(port, n int) devicePortOverride {
+		return devicePortOverride{PortIDX: port, OpMode: "aggregate", AggregateNumPorts: n}
+	}
+	named := func(port int, name string) devicePortOverride {
+		return devicePortOverride{PortIDX: port, Name: name}
+	}
+
+	for i, c := range []struct {
+		uplink   int
+		current  []devicePortOverride
+		final    []devicePortOverride
+		expected []devicePortOverride
+		deferred bool
+	}{
+		// uplink untouched
+		{1, nil, []devicePortOverride{named(2, "a")}, nil, false},
+		{1, []devicePortOverride{named(1, "uplink")}, []devicePortOverride{named(1, "uplink"), named(2, "a")}, nil, false},
+
+		// uplink added to an aggregate
+		{
+			2,
+			[]devicePortOverride{named(5, "a")},
+			[]devicePortOverride{aggregate(1, 2), named(5, "b")},
+			[]devicePortOverride{named(5, "b")},
+			true,
+		},
+		// aggregate of the uplink grows, the overlapping ports follow
+		{
+			1,
+			[]devicePortOverride{aggregate(1, 2), named(3, "a")},
+			[]devicePortOverride{aggregate(1, 4), named(6, "b")},
+			[]devicePortOverride{aggregate(1, 2), named(3, "a"), named(6, "b")},
+			true,
+		},
+		// aggregate of the uplink removed
+		{
+			2,
+			[]devicePortOverride{aggregate(1, 2)},
+			nil,
+			[]devicePortOverride{aggregate(1, 2)},
+			true,
+		},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual, deferred := uplinkInterimPortOverrides(c.uplink, c.current, c.final)
+			if deferred != c.deferred {
+				t.Fatalf("expected deferred %t, got %t", c.deferred, deferred)
+			}
+			if fmt.Sprint(actual) != fmt.Sprint(c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, actual)
+			}
+		})
+	}
+}
+
+// deviceEventClient records the port override updates and the device states
+// seen while polling, in order.
+type deviceEventClient struct {
+	unifiClient
+
+	mac    string
+	events []string
+}
+
+func (c *deviceEventClient) UpdateDevicePortOverrides(ctx context.Context, site, id string, overrides []devicePortOverride) ([]devicePortOverride, error) {
+	c.events = append(c.events, "update")
+	return c.unifiClient.UpdateDevicePortOverrides(ctx, site, id, overrides)
+}
+
+func (c *deviceEventClient) ListDeviceStatus(ctx context.Context, site string) ([]deviceStatus, error) {
+	devices, err := c.unifiClient.ListDeviceStatus(ctx, site)
+	for _, dev := range devices {
+		if dev.MAC == c.mac {
+			c.events = append(c.events, deviceStateName(dev.State))
+		}
+	}
+	return devices, err
+}
+
+func TestDevice_fake_uplinkReconnect(t *testing.T) {
+	defer func(interval time.Duration) { devicePollInterval = interval }(devicePollInterval)
+	devicePollInterval = time.Millisecond
+
+	c, s := newFakeClient(t)
+
+	ports := []interface{}{}
+	for i := 1; i <= 8; i++ {
+		ports = append(ports, map[string]interface{}{"port_idx": i})
+	}
+	id, err := s.AddObject("default", "device", map[string]interface{}{
+		"mac":        "00:00:5e:00:53:26",
+		"name":       "switch",
+		"type":       "usw",
+		"state":      1,
+		"uplink":     map[string]interface{}{"type": "wire", "port_idx": 8},
+		"port_table": ports,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := &deviceEventClient{unifiClient: c.c, mac: "00:00:5e:00:53:26"}
+	_, err = resourceDeviceUpdatePortOverrides(context.Background(), &client{c: events, site: "default"}, "default", id, "00:00:5e:00:53:26", []devicePortOverride{
+		{PortIDX: 1, Name: "nas"},
+		{PortIDX: 7, OpMode: "aggregate", AggregateNumPorts: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the uplink aggregate is only sent after the other change is provisioned,
+	// and the update waits for the device to come back
+	expected := []string{
+		"connected",
+		"update", "provisioning", "connected",
+		"update", "provisioning", "connected",
+	}
+	if fmt.Sprint(events.events) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v, got %v", expected, events.events)
+	}
+}
+
+// connectedDeviceClient reports every device as connected, hiding the
+// provisioning state, and optionally the configuration version as well.
+type connectedDeviceClient struct {
+	unifiClient
+
+	hideCfgVersion bool
+}
+
+func (c *connectedDeviceClient) ListDeviceStatus(ctx context.Context, site string) ([]deviceStatus, error) {
+	devices, err := c.unifiClient.ListDeviceStatus(ctx, site)
+	for i := range devices {
+		devices[i].State = deviceStateConnected
+		if c.hideCfgVersion {
+			devices[i].CfgVersion = ""
+		}
+	}
+	return devices, err
+}
+
+func TestDevice_fake_uplinkStaysConnected(t *testing.T) {
+	defer func(interval time.Duration) { devicePollInterval = interval }(devicePollInterval)
+	devicePollInterval = time.Millisecond
+	defer func(timeout time.Duration) { deviceProvisionStartTimeout = timeout }(deviceProvisionStartTimeout)
+
+	for _, tc := range []struct {
+		name           string
+		hideCfgVersion bool
+		startTimeout   time.Duration
+	}{
+		// the new configuration version shows the change was provisioned
+		// between two polls, so the start timeout is never reached
+		{"provisioned between polls", false, time.Hour},
+		// the device is never seen provisioning, so the wait gives up
+		{"not provisioned", true, 10 * time.Millisecond},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			deviceProvisionStartTimeout = tc.startTimeout
+
+			c, s := newFakeClient(t)
+
+			ports := []interface{}{}
+			for i := 1; i <= 8; i++ {
+				ports = append(ports, map[string]interface{}{"port_idx": i})
+			}
+			id, err := s.AddObject("default", "device", map[string]interface{}{
+				"mac":        "00:00:5e:00:53:27",
+				"name":       "switch",
+				"type":       "usw",
+				"state":      1,
+				"cfgversion": "0000000000000000",
+				"uplink":     map[string]interface{}{"type": "wire", "port_idx": 8},
+				"port_table": ports,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			connected := &connectedDeviceClient{unifiClient: c.c, hideCfgVersion: tc.hideCfgVersion}
+			_, err = resourceDeviceUpdatePortOverrides(ctx, &client{c: connected, site: "default"}, "default", id, "00:00:5e:00:53:27", []devicePortOverride{
+				{PortIDX: 1, Name: "nas"},
+				{PortIDX: 7, OpMode: "aggregate", AggregateNumPorts: 2},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			overrides, err := c.c.GetDevicePortOverrides(ctx, "default", id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(overrides) != 2 {
+				t.Fatalf("expected 2 port overrides, got %d", len(overrides))
+			}
+		})
+	}
+}
+
+func TestDevice_fake_aggregateMirror(t *testing.T) {
+	defer func(interval time.Duration) { devicePollInterval = interval }(devicePollInterval)
+	devicePollInterval = time.Millisecond
+
+	c, s := newFakeClient(t)
+
+	ports := []interface{}{}
+	for i := 1; i <= 8; i++ {
+		ports = append(ports, map[string]interface{}{"port_idx": i})
+	}
+	id, err := s.AddObject("default", "device", map[string]interface{}{
+		"mac":        "00:00:5e:00:53:25",
+		"name":       "switch",
+		"type":       "usw",
+		"state":      1,
+		"uplink":     map[string]interface{}{"type": "wire", "port_idx": 8},
+		"port_table": ports,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_device",
+		steps: []fakeStep{
+			{
+				// create only starts managing the device
+				config: map[string]interface{}{
+					"mac": "00:00:5e:00:53:25",
+				},
+			},
+			{
+				config: map[string]interface{}{
+					"mac": "00:00:5e:00:53:25",
+					"port_override": []interface{}{
+						map[string]interface{}{
+							"number": 5,
+							"name":   "nas",
+						},
+					},
+					"aggregate": []interface{}{
+						map[string]interface{}{
+							"port":      1,
+							"num_ports": 2,
+						},
+						map[string]interface{}{
+							"port":      7,
+							"num_ports": 2,
+						},
+					},
+					"mirror": []interface{}{
+						map[string]interface{}{
+							"port":        4,
+							"source_port": 3,
+						},
+					},
+				},
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					fakeCheckAttr("port_override.#", "1")(t, state)
+					fakeCheckAttr("aggregate.#", "2")(t, state)
+					fakeCheckAttr("mirror.#", "1")(t, state)
+
+					overrides := s.Object("default", "device", id)["port_overrides"].([]interface{})
+					if len(overrides) != 4 {
+						t.Fatalf("expected 4 port overrides, got %d", len(overrides))
+					}
+					for i, expected := range []map[string]interface{}{
+						{"port_idx": float64(1), "op_mode": "aggregate", "aggregate_num_ports": float64(2)},
+						{"port_idx": float64(4), "op_mode": "mirror", "mirror_port_idx": float64(3)},
+						{"port_idx": float64(5), "name": "nas"},
+						{"port_idx": float64(7), "op_mode": "aggregate", "aggregate_num_ports": float64(2)},
+					} {
+						po := overrides[i].(map[string]interface{})
+						for k, v := range expected {
+							if po[k] != v {
+								t.Errorf("port override %d: expected %s = %v, got %v", i, k, v, po[k])
+							}
+						}
+					}
+				},
+				importStateVerify: true,
+			},
+			{
+				// removing the uplink aggregate
+				config: map[string]interface{}{
+					"mac": "00:00:5e:00:53:25",
+				},
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					fakeCheckAttr("aggregate.#", "0")(t, state)
+					fakeCheckAttr("mirror.#", "0")(t, state)
+					if overrides := s.Object("default", "device", id)["port_overrides"].([]interface{}); len(overrides) != 0 {
+						t.Errorf("expected the port overrides to be cleared, got %v", overrides)
+					}
+				},
+			},
+		},
+		// devices are only removed from state
+		skipCheckDestroy: true,
+	})
+
+	for _, tc := range []struct {
+		config   map[string]interface{}
+		expected string
+	}{
+		{
+			map[string]interface{}{
+				"aggregate": []interface{}{
+					map[string]interface{}{"port": 1, "num_ports": 3},
+				},
+				"mirror": []interface{}{
+					map[string]interface{}{"port": 3, "source_port": 4},
+				},
+			},
+			"port 3 is used by both aggregate 1 and mirror 3",
+		},
+		{
+			map[string]interface{}{
+				"mirror": []interface{}{
+					map[string]interface{}{"port": 4, "source_port": 4},
+				},
+			},
+			"mirror 4 cannot mirror itself",
+		},
+		{
+			map[string]interface{}{
+				"mirror": []interface{}{
+					map[string]interface{}{"port": 4, "source_port": 3},
+					map[string]interface{}{"port": 5, "source_port": 4},
+				},
+			},
+			"port 4 cannot be mirrored as it is used by mirror 4",
+		},
+		{
+			map[string]interface{}{
+				"aggregate": []interface{}{
+					map[string]interface{}{"port": 7, "num_ports": 4},
+				},
+			},
+			"aggregate 7: device \"00:00:5e:00:53:25\" does not have 4 ports from port 7",
+		},
+		{
+			map[string]interface{}{
+				"port_override": []interface{}{
+					map[string]interface{}{"number": 2, "name": "nas"},
+				},
+				"aggregate": []interface{}{
+					map[string]interface{}{"port": 1, "num_ports": 2},
+				},
+			},
+			"port_override 2: the port is used by aggregate 1",
+		},
+		{
+			map[string]interface{}{
+				"port_override": []interface{}{
+					map[string]interface{}{"number": 4, "op_mode": "switch"},
+				},
+				"mirror": []interface{}{
+					map[string]interface{}{"port": 4, "source_port": 3},
+				},
+			},
+			"port_override 4: the port is used by mirror 4",
+		},
+		{
+			map[string]interface{}{
+				"mirror": []interface{}{
+					map[string]interface{}{"port": 8, "source_port": 1},
+				},
+			},
+			"port 8 is the uplink",
+		},
+	} {
+		tc.config["mac"] = "00:00:5e:00:53:25"
+		err := testFakePlanError(t, c, "unifi_device", tc.config)
+		if err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("expected %q, got %v", tc.expected, err)
+		}
+	}
+}
@@ -3,6 +3,7 @@
 import (
 	"context"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/paultyng/go-unifi/unifi"
 )
@@ -11,12 +12,18 @@ func resourceDynamicDNS() *schema.Resource {
 	return &schema.Resource{
 		Description: "`unifi_dynamic_dns` manages dynamic DNS settings for different providers.",
 
-		Create: resourceDynamicDNSCreate,
-		Read:   resourceDynamicDNSRead,
-		Update: resourceDynamicDNSUpdate,
-		Delete: resourceDynamicDNSDelete,
+		CreateContext: resourceDynamicDNSCreate,
+		ReadContext:   resourceDynamicDNSRead,
+		UpdateContext: resourceDynamicDNSUpdate,
+		DeleteContext: resourceDynamicDNSDelete,
 		Importer: &schema.ResourceImporter{
-			State: importSiteAndID,
+			StateContext: importSiteAndID,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -72,12 +79,12 @@ func resourceDynamicDNS() *schema.Resource {
 	}
 }
 
-func resourceDynamicDNSCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceDynamicDNSCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourceDynamicDNSGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	site := d.Get("site").(string)
@@ -85,14 +92,14 @@ func resourceDynamicDNSCreate(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	resp, err := c.c.CreateDynamicDNS(context.TODO(), site, req)
+	resp, err := c.c.CreateDynamicDNS(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(resp.ID)
 
-	return resourceDynamicDNSSetResourceData(resp, d, site)
+	return diag.FromErr(resourceDynamicDNSSetResourceData(resp, d, site))
 }
 
 func resourceDynamicDNSGetResourceData(d *schema.ResourceData) (*unifi.DynamicDNS, error) {
@@ -123,7 +130,7 @@ func resourceDynamicDNSSetResourceData(resp *unifi.DynamicDNS, d *schema.Resourc
 	return nil
 }
 
-func resourceDynamicDNSRead(d *schema.ResourceData, meta interface{}) error {
+func resourceDynamicDNSRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -133,24 +140,24 @@ func resourceDynamicDNSRead(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	resp, err := c.c.GetDynamicDNS(context.TODO(), site, id)
+	resp, err := c.c.GetDynamicDNS(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		d.SetId("")
 		return nil
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceDynamicDNSSetResourceData(resp, d, site)
+	return diag.FromErr(resourceDynamicDNSSetResourceData(resp, d, site))
 }
 
-func resourceDynamicDNSUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceDynamicDNSUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourceDynamicDNSGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	req.ID = d.Id()
@@ -161,15 +168,15 @@ func resourceDynamicDNSUpdate(d *schema.ResourceData, meta interface{}) error {
 	}
 	req.SiteID = site
 
-	resp, err := c.c.UpdateDynamicDNS(context.TODO(), site, req)
+	resp, err := c.c.UpdateDynamicDNS(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceDynamicDNSSetResourceData(resp, d, site)
+	return diag.FromErr(resourceDynamicDNSSetResourceData(resp, d, site))
 }
 
-func resourceDynamicDNSDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceDynamicDNSDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -178,9 +185,9 @@ func resourceDynamicDNSDelete(d *schema.ResourceData, meta interface{}) error {
 	if site == "" {
 		site = c.site
 	}
-	err := c.c.DeleteDynamicDNS(context.TODO(), site, id)
+	err := c.c.DeleteDynamicDNS(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		return nil
 	}
-	return err
+	return diag.FromErr(err)
 }
@@ -34,3 +34,24 @@ func TestAccDynamicDNS_dyndns(t *testing.T) {
 	password = "password"
 }
 `
+
+func TestDynamicDNS_fake(t *testing.T) {
+	c, _ := newFakeClient(t)
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_dynamic_dns",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"service":   "dyndns",
+					"host_name": "test.example.com",
+					"server":    "dyndns.example.com",
+					"login":     "testuser",
+					"password":  "password",
+				},
+				check:             fakeCheckAttr("interface", "wan"),
+				importStateVerify: true,
+			},
+		},
+	})
+}
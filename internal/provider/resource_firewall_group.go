@@ -5,6 +5,7 @@
 	"errors"
 	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/paultyng/go-unifi/unifi"
@@ -14,12 +15,19 @@ func resourceFirewallGroup() *schema.Resource {
 	return &schema.Resource{
 		Description: "`unifi_firewall_group` manages groups of addresses or ports for use in firewall rules (`unifi_firewall_rule`).",
 
-		Create: resourceFirewallGroupCreate,
-		Read:   resourceFirewallGroupRead,
-		Update: resourceFirewallGroupUpdate,
-		Delete: resourceFirewallGroupDelete,
+		CreateContext: resourceFirewallGroupCreate,
+		ReadContext:   resourceFirewallGroupRead,
+		UpdateContext: resourceFirewallGroupUpdate,
+		DeleteContext: resourceFirewallGroupDelete,
+		CustomizeDiff: resourceFirewallGroupCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			State: importSiteAndID,
+			StateContext: importSiteAndName("firewall group", resourceFirewallGroupImportNames),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -47,21 +55,47 @@ func resourceFirewallGroup() *schema.Resource {
 				ValidateFunc: validation.StringInSlice([]string{"address-group", "port-group", "ipv6-address-group"}, false),
 			},
 			"members": {
-				Description: "The members of the firewall group.",
-				Type:        schema.TypeSet,
-				Required:    true,
-				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The members of the firewall group. Members of an `ipv6-address-group` must be IPv6 " +
+					"addresses or CIDRs.",
+				Type:     schema.TypeSet,
+				Required: true,
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					DiffSuppressFunc: ipDiffSuppress,
+				},
+				Set: firewallGroupMemberHash,
 			},
 		},
 	}
 }
 
-func resourceFirewallGroupCreate(d *schema.ResourceData, meta interface{}) error {
+// firewallGroupMemberHash hashes the canonical form of the addresses so the
+// same IPv6 address written differently is the same member.
+func firewallGroupMemberHash(v interface{}) int {
+	return schema.HashString(ipNormalize(v.(string)))
+}
+
+func resourceFirewallGroupCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Get("type").(string) != "ipv6-address-group" || !d.NewValueKnown("members") {
+		return nil
+	}
+
+	for _, m := range d.Get("members").(*schema.Set).List() {
+		member := m.(string)
+		if ipFamily(member) != 6 {
+			return fmt.Errorf("members of an ipv6-address-group must be IPv6 addresses or CIDRs, got %q", member)
+		}
+	}
+
+	return nil
+}
+
+func resourceFirewallGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourceFirewallGroupGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	site := d.Get("site").(string)
@@ -69,19 +103,19 @@ func resourceFirewallGroupCreate(d *schema.ResourceData, meta interface{}) error
 		site = c.site
 	}
 
-	resp, err := c.c.CreateFirewallGroup(context.TODO(), site, req)
+	resp, err := c.c.CreateFirewallGroup(ctx, site, req)
 	if err != nil {
 		var apiErr *unifi.APIError
 		if errors.As(err, &apiErr) && apiErr.Message == "api.err.FirewallGroupExisted" {
-			return fmt.Errorf("firewall groups must have unique names: %w", err)
+			return diag.FromErr(fmt.Errorf("firewall groups must have unique names: %w", err))
 		}
 
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(resp.ID)
 
-	return resourceFirewallGroupSetResourceData(resp, d, site)
+	return diag.FromErr(resourceFirewallGroupSetResourceData(resp, d, site))
 }
 
 func resourceFirewallGroupGetResourceData(d *schema.ResourceData) (*unifi.FirewallGroup, error) {
@@ -90,6 +124,12 @@ func resourceFirewallGroupGetResourceData(d *schema.ResourceData) (*unifi.Firewa
 		return nil, err
 	}
 
+	if d.Get("type").(string) == "ipv6-address-group" {
+		for i, m := range members {
+			members[i] = ipNormalize(m)
+		}
+	}
+
 	return &unifi.FirewallGroup{
 		Name:         d.Get("name").(string),
 		GroupType:    d.Get("type").(string),
@@ -101,12 +141,12 @@ func resourceFirewallGroupSetResourceData(resp *unifi.FirewallGroup, d *schema.R
 	d.Set("site", site)
 	d.Set("name", resp.Name)
 	d.Set("type", resp.GroupType)
-	d.Set("members", stringSliceToSet(resp.GroupMembers))
+	d.Set("members", schema.NewSet(firewallGroupMemberHash, stringSliceToList(resp.GroupMembers)))
 
 	return nil
 }
 
-func resourceFirewallGroupRead(d *schema.ResourceData, meta interface{}) error {
+func resourceFirewallGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -116,24 +156,24 @@ func resourceFirewallGroupRead(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	resp, err := c.c.GetFirewallGroup(context.TODO(), site, id)
+	resp, err := c.c.GetFirewallGroup(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		d.SetId("")
 		return nil
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceFirewallGroupSetResourceData(resp, d, site)
+	return diag.FromErr(resourceFirewallGroupSetResourceData(resp, d, site))
 }
 
-func resourceFirewallGroupUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceFirewallGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourceFirewallGroupGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	req.ID = d.Id()
@@ -144,15 +184,15 @@ func resourceFirewallGroupUpdate(d *schema.ResourceData, meta interface{}) error
 	}
 	req.SiteID = site
 
-	resp, err := c.c.UpdateFirewallGroup(context.TODO(), site, req)
+	resp, err := c.c.UpdateFirewallGroup(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceFirewallGroupSetResourceData(resp, d, site)
+	return diag.FromErr(resourceFirewallGroupSetResourceData(resp, d, site))
 }
 
-func resourceFirewallGroupDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceFirewallGroupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -162,9 +202,22 @@ func resourceFirewallGroupDelete(d *schema.ResourceData, meta interface{}) error
 		site = c.site
 	}
 
-	err := c.c.DeleteFirewallGroup(context.TODO(), site, id)
+	err := c.c.DeleteFirewallGroup(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		return nil
 	}
-	return err
+	return diag.FromErr(err)
+}
+
+func resourceFirewallGroupImportNames(ctx context.Context, c unifiClient, site string) ([]importName, error) {
+	groups, err := c.ListFirewallGroup(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]importName, 0, len(groups))
+	for _, o := range groups {
+		names = append(names, importName{id: o.ID, name: o.Name})
+	}
+	return names, nil
 }
@@ -7,6 +7,7 @@
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccFirewallGroup_port_group(t *testing.T) {
@@ -100,3 +101,67 @@ func testAccFirewallGroupConfig(name, ty string, members []string) string {
 	members = []
 }
 `
+
+func TestFirewallGroup_fake(t *testing.T) {
+	c, _ := newFakeClient(t)
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_firewall_group",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"name":    "testag",
+					"type":    "address-group",
+					"members": []interface{}{"10.0.0.1", "10.0.0.2"},
+				},
+				check:             fakeCheckAttr("members.#", "2"),
+				importStateVerify: true,
+			},
+			{
+				config: map[string]interface{}{
+					"name":    "testag",
+					"type":    "address-group",
+					"members": []interface{}{"10.0.0.0/24"},
+				},
+				check:             fakeCheckAttr("members.#", "1"),
+				importStateVerify: true,
+				importStateID:     "default:name=testag",
+			},
+		},
+	})
+}
+
+func TestFirewallGroup_fake_ipv6(t *testing.T) {
+	c, _ := newFakeClient(t)
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_firewall_group",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"name":    "testv6",
+					"type":    "ipv6-address-group",
+					"members": []interface{}{"2001:DB8:0::1", "2001:db8:1::/64"},
+				},
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					fakeCheckAttr("members.#", "2")(t, state)
+					for k, v := range state.Attributes {
+						if strings.HasPrefix(k, "members.") && k != "members.#" && v != "2001:db8::1" && v != "2001:db8:1::/64" {
+							t.Errorf("unexpected member %s = %q", k, v)
+						}
+					}
+				},
+				importStateVerify: true,
+			},
+		},
+	})
+
+	err := testFakePlanError(t, c, "unifi_firewall_group", map[string]interface{}{
+		"name":    "testv6",
+		"type":    "ipv6-address-group",
+		"members": []interface{}{"10.0.0.1"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "must be IPv6 addresses or CIDRs") {
+		t.Fatalf("expected an invalid member error, got %v", err)
+	}
+}
@@ -6,23 +6,32 @@
 	"fmt"
 	"regexp"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/paultyng/go-unifi/unifi"
 )
 
+var firewallRulesets = []string{"WAN_IN", "WAN_OUT", "WAN_LOCAL", "LAN_IN", "LAN_OUT", "LAN_LOCAL", "GUEST_IN", "GUEST_OUT", "GUEST_LOCAL", "WANv6_IN", "WANv6_OUT", "WANv6_LOCAL", "LANv6_IN", "LANv6_OUT", "LANv6_LOCAL", "GUESTv6_IN", "GUESTv6_OUT", "GUESTv6_LOCAL"}
+
 var firewallRuleProtocolRegexp = regexp.MustCompile("^$|all|([0-9]|[1-9][0-9]|1[0-9]{2}|2[0-4][0-9]|25[0-5])|tcp_udp|ah|ax.25|dccp|ddp|egp|eigrp|encap|esp|etherip|fc|ggp|gre|hip|hmp|icmp|idpr-cmtp|idrp|igmp|igp|ip|ipcomp|ipencap|ipip|ipv6|ipv6-frag|ipv6-icmp|ipv6-nonxt|ipv6-opts|ipv6-route|isis|iso-tp4|l2tp|manet|mobility-header|mpls-in-ip|ospf|pim|pup|rdp|rohc|rspf|rsvp|sctp|shim6|skip|st|tcp|udp|udplite|vmtp|vrrp|wesp|xns-idp|xtp")
 
 func resourceFirewallRule() *schema.Resource {
 	return &schema.Resource{
 		Description: "`unifi_firewall_rule` manages an individual firewall rule on the gateway.",
 
-		Create: resourceFirewallRuleCreate,
-		Read:   resourceFirewallRuleRead,
-		Update: resourceFirewallRuleUpdate,
-		Delete: resourceFirewallRuleDelete,
+		CreateContext: resourceFirewallRuleCreate,
+		ReadContext:   resourceFirewallRuleRead,
+		UpdateContext: resourceFirewallRuleUpdate,
+		DeleteContext: resourceFirewallRuleDelete,
 		Importer: &schema.ResourceImporter{
-			State: importSiteAndID,
+			StateContext: importSiteAndName("firewall rule", resourceFirewallRuleImportNames),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -56,13 +65,16 @@ func resourceFirewallRule() *schema.Resource {
 					"`LANv6_LOCAL`, `GUESTv6_IN`, `GUESTv6_OUT`, or `GUESTv6_LOCAL`.",
 				Type:         schema.TypeString,
 				Required:     true,
-				ValidateFunc: validation.StringInSlice([]string{"WAN_IN", "WAN_OUT", "WAN_LOCAL", "LAN_IN", "LAN_OUT", "LAN_LOCAL", "GUEST_IN", "GUEST_OUT", "GUEST_LOCAL", "WANv6_IN", "WANv6_OUT", "WANv6_LOCAL", "LANv6_IN", "LANv6_OUT", "LANv6_LOCAL", "GUESTv6_IN", "GUESTv6_OUT", "GUESTv6_LOCAL"}, false),
+				ValidateFunc: validation.StringInSlice(firewallRulesets, false),
 			},
 			"rule_index": {
 				Description: "The index of the rule. Must be >= 2000 < 3000 or >= 4000 < 5000.",
 				Type:        schema.TypeInt,
 				Required:    true,
-				// 2[0-9]{3}|4[0-9]{3}
+				ValidateFunc: validation.Any(
+					validation.IntBetween(2000, 2999),
+					validation.IntBetween(4000, 4999),
+				),
 			},
 			"protocol": {
 				Description:  "The protocol of the rule.",
@@ -173,12 +185,12 @@ func resourceFirewallRule() *schema.Resource {
 	}
 }
 
-func resourceFirewallRuleCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceFirewallRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourceFirewallRuleGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	site := d.Get("site").(string)
@@ -186,19 +198,19 @@ func resourceFirewallRuleCreate(d *schema.ResourceData, meta interface{}) error
 		site = c.site
 	}
 
-	resp, err := c.c.CreateFirewallRule(context.TODO(), site, req)
+	resp, err := c.c.CreateFirewallRule(ctx, site, req)
 	if err != nil {
 		var apiErr *unifi.APIError
 		if errors.As(err, &apiErr) && apiErr.Message == "api.err.FirewallGroupTypeExists" {
-			return fmt.Errorf("firewall rule groups must be of different group types (ie. a port group and address group): %w", err)
+			return diag.FromErr(fmt.Errorf("firewall rule groups must be of different group types (ie. a port group and address group): %w", err))
 		}
 
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(resp.ID)
 
-	return resourceFirewallRuleSetResourceData(resp, d, site)
+	return diag.FromErr(resourceFirewallRuleSetResourceData(resp, d, site))
 }
 
 func resourceFirewallRuleGetResourceData(d *schema.ResourceData) (*unifi.FirewallRule, error) {
@@ -273,7 +285,7 @@ func resourceFirewallRuleSetResourceData(resp *unifi.FirewallRule, d *schema.Res
 	return nil
 }
 
-func resourceFirewallRuleRead(d *schema.ResourceData, meta interface{}) error {
+func resourceFirewallRuleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -283,24 +295,24 @@ func resourceFirewallRuleRead(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	resp, err := c.c.GetFirewallRule(context.TODO(), site, id)
+	resp, err := c.c.GetFirewallRule(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		d.SetId("")
 		return nil
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceFirewallRuleSetResourceData(resp, d, site)
+	return diag.FromErr(resourceFirewallRuleSetResourceData(resp, d, site))
 }
 
-func resourceFirewallRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceFirewallRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourceFirewallRuleGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	req.ID = d.Id()
@@ -311,15 +323,15 @@ func resourceFirewallRuleUpdate(d *schema.ResourceData, meta interface{}) error
 	}
 	req.SiteID = site
 
-	resp, err := c.c.UpdateFirewallRule(context.TODO(), site, req)
+	resp, err := c.c.UpdateFirewallRule(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceFirewallRuleSetResourceData(resp, d, site)
+	return diag.FromErr(resourceFirewallRuleSetResourceData(resp, d, site))
 }
 
-func resourceFirewallRuleDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceFirewallRuleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -328,9 +340,22 @@ func resourceFirewallRuleDelete(d *schema.ResourceData, meta interface{}) error
 	if site == "" {
 		site = c.site
 	}
-	err := c.c.DeleteFirewallRule(context.TODO(), site, id)
+	err := c.c.DeleteFirewallRule(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		return nil
 	}
-	return err
+	return diag.FromErr(err)
+}
+
+func resourceFirewallRuleImportNames(ctx context.Context, c unifiClient, site string) ([]importName, error) {
+	rules, err := c.ListFirewallRule(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]importName, 0, len(rules))
+	for _, o := range rules {
+		names = append(names, importName{id: o.ID, name: o.Name})
+	}
+	return names, nil
 }
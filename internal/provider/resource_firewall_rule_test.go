@@ -261,3 +261,38 @@ func TestAccFirewallRule_address_and_port_group(t *testing.T) {
 // 	dst_address = "192.168.1.1"
 // }
 // `
+
+func TestFirewallRule_fake(t *testing.T) {
+	c, _ := newFakeClient(t)
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_firewall_rule",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"name":        "tf acc",
+					"action":      "accept",
+					"ruleset":     "LAN_IN",
+					"rule_index":  2010,
+					"protocol":    "all",
+					"dst_address": "192.168.1.1",
+				},
+				importStateVerify: true,
+			},
+			{
+				config: map[string]interface{}{
+					"name":        "tf acc",
+					"action":      "drop",
+					"ruleset":     "LAN_IN",
+					"rule_index":  2010,
+					"protocol":    "tcp",
+					"dst_address": "192.168.1.1",
+					"dst_port":    "53",
+				},
+				check:             fakeCheckAttr("dst_port", "53"),
+				importStateVerify: true,
+				importStateID:     "name=tf acc",
+			},
+		},
+	})
+}
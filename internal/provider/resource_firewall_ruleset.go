@@ -0,0 +1,447 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/paultyng/go-unifi/unifi"
+)
+
+// firewallRuleIndexRangeSize is the number of rule indexes available to
+// custom rules before (2000) or after (4000) the predefined rules.
+const firewallRuleIndexRangeSize = 1000
+
+func resourceFirewallRuleset() *schema.Resource {
+	return &schema.Resource{
+		Description: "`unifi_firewall_ruleset` manages an ordered list of firewall rules in one ruleset. The rule " +
+			"indexes are assigned from the order of the `rule` blocks, and rules are matched by name so " +
+			"reordering updates the existing rules instead of replacing them.\n\n" +
+			"Rules of the ruleset that are not managed by this resource keep their indexes, which are skipped " +
+			"when assigning indexes.\n\n" +
+			"Importing a ruleset manages all of its rules in the index range of `rule_index_base`, which is " +
+			"`2000` unless the import ID ends with `/4000`.",
+
+		CreateContext: resourceFirewallRulesetCreate,
+		ReadContext:   resourceFirewallRulesetRead,
+		UpdateContext: resourceFirewallRulesetUpdate,
+		DeleteContext: resourceFirewallRulesetDelete,
+		CustomizeDiff: resourceFirewallRulesetCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceFirewallRulesetImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Description: "The ID of the firewall ruleset, this is the name of the ruleset.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"site": {
+				Description: "The name of the site to associate the firewall rules with.",
+				Type:        schema.TypeString,
+				Computed:    true,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"ruleset": {
+				Description: "The ruleset to manage. This is from the perspective of the security gateway. " +
+					"Must be one of `WAN_IN`, `WAN_OUT`, `WAN_LOCAL`, `LAN_IN`, `LAN_OUT`, `LAN_LOCAL`, `GUEST_IN`, " +
+					"`GUEST_OUT`, `GUEST_LOCAL`, `WANv6_IN`, `WANv6_OUT`, `WANv6_LOCAL`, `LANv6_IN`, `LANv6_OUT`, " +
+					"`LANv6_LOCAL`, `GUESTv6_IN`, `GUESTv6_OUT`, or `GUESTv6_LOCAL`.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(firewallRulesets, false),
+			},
+			"rule_index_base": {
+				Description: "The first rule index to assign. Must be `2000` to place the rules before the predefined " +
+					"rules, or `4000` to place them after.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      2000,
+				ValidateFunc: validation.IntInSlice([]int{2000, 4000}),
+			},
+			"rule": {
+				Description: "The rules of the ruleset, in order. Rule names must be unique within the ruleset.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    firewallRuleIndexRangeSize,
+				Elem: &schema.Resource{
+					Schema: resourceFirewallRulesetRuleSchema(),
+				},
+			},
+			"rule_ids": {
+				Description: "The IDs of the firewall rules, by rule name.",
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// resourceFirewallRulesetRuleSchema returns the attributes of the
+// unifi_firewall_rule resource that are set on each rule of a ruleset.
+func resourceFirewallRulesetRuleSchema() map[string]*schema.Schema {
+	s := resourceFirewallRule().Schema
+	for _, k := range []string{"id", "site", "ruleset", "rule_index"} {
+		delete(s, k)
+	}
+	return s
+}
+
+func resourceFirewallRulesetCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	oldRules, newRules := d.GetChange("rule")
+
+	names := map[string]bool{}
+	namesKnown := d.NewValueKnown("rule")
+	for i, raw := range newRules.([]interface{}) {
+		if !d.NewValueKnown(fmt.Sprintf("rule.%d.name", i)) {
+			namesKnown = false
+		}
+		rule, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := rule["name"].(string)
+		if name == "" {
+			continue
+		}
+		if names[name] {
+			return fmt.Errorf("rule names must be unique within a ruleset, found %q more than once", name)
+		}
+		names[name] = true
+	}
+
+	// rules are updated in place, so the rule IDs only change when rules are
+	// added, removed or renamed
+	if !namesKnown || !firewallRulesetSameNames(oldRules.([]interface{}), names) {
+		return d.SetNewComputed("rule_ids")
+	}
+	return nil
+}
+
+func firewallRulesetSameNames(rules []interface{}, names map[string]bool) bool {
+	if len(rules) != len(names) {
+		return false
+	}
+	for _, raw := range rules {
+		rule, ok := raw.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if name, _ := rule["name"].(string); !names[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// resourceFirewallRulesetImport imports the rules of a ruleset, the import ID
+// is the ruleset, optionally prefixed with the site and followed by the rule
+// index base, for example default:LAN_IN/4000.
+func resourceFirewallRulesetImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	c := meta.(*client)
+
+	if _, err := importSiteAndID(ctx, d, meta); err != nil {
+		return nil, err
+	}
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+
+	ruleset := d.Id()
+	base := 2000
+	if i := strings.LastIndex(ruleset, "/"); i >= 0 {
+		var err error
+		if base, err = strconv.Atoi(ruleset[i+1:]); err != nil || (base != 2000 && base != 4000) {
+			return nil, fmt.Errorf("rule index base must be 2000 or 4000, got %q", ruleset[i+1:])
+		}
+		ruleset = ruleset[:i]
+	}
+	if _, errs := validation.StringInSlice(firewallRulesets, false)(ruleset, "ruleset"); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	rules, err := c.c.ListFirewallRule(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]string{}
+	for _, rule := range rules {
+		if rule.Ruleset != ruleset || rule.RuleIndex < base || rule.RuleIndex >= base+firewallRuleIndexRangeSize {
+			continue
+		}
+		if _, ok := ids[rule.Name]; ok {
+			return nil, fmt.Errorf("rule names must be unique within a ruleset, found %q more than once", rule.Name)
+		}
+		ids[rule.Name] = rule.ID
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no rules found in %s from rule index %d", ruleset, base)
+	}
+
+	d.SetId(ruleset)
+	d.Set("ruleset", ruleset)
+	d.Set("rule_index_base", base)
+	d.Set("rule_ids", ids)
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceFirewallRulesetCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := resourceFirewallRulesetApply(ctx, d, meta); diags.HasError() {
+		return diags
+	}
+
+	return resourceFirewallRulesetRead(ctx, d, meta)
+}
+
+func resourceFirewallRulesetUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := resourceFirewallRulesetApply(ctx, d, meta); diags.HasError() {
+		return diags
+	}
+
+	return resourceFirewallRulesetRead(ctx, d, meta)
+}
+
+// resourceFirewallRulesetApply creates, updates and deletes the rules of the
+// ruleset to match the configuration. Rules moving to a different index are
+// first moved to a free temporary index, so no two rules share an index while
+// the rules are reordered.
+func resourceFirewallRulesetApply(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client)
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+	ruleset := d.Get("ruleset").(string)
+	base := d.Get("rule_index_base").(int)
+
+	// the ID is set first so rules created before a failure are kept in state
+	d.SetId(ruleset)
+
+	// the rules previously managed, by name. The IDs of the rules are kept up
+	// to date as they are deleted and created, so a failed apply only records
+	// the rules that exist.
+	oldIDs, _ := d.GetChange("rule_ids")
+	managed := map[string]string{}
+	ids := map[string]string{}
+	for name, id := range oldIDs.(map[string]interface{}) {
+		managed[name] = id.(string)
+		ids[name] = id.(string)
+	}
+	defer d.Set("rule_ids", ids)
+
+	all, err := c.c.ListFirewallRule(ctx, site)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	current := map[string]*unifi.FirewallRule{}
+	for i := range all {
+		current[all[i].ID] = &all[i]
+	}
+
+	newRules := d.Get("rule").([]interface{})
+	desired := make([]*unifi.FirewallRule, 0, len(newRules))
+	desiredNames := map[string]bool{}
+	for _, raw := range newRules {
+		rule, err := resourceFirewallRulesetGetRule(raw.(map[string]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		rule.Ruleset = ruleset
+		if id, ok := managed[rule.Name]; ok && current[id] != nil {
+			rule.ID = id
+			rule.SiteID = site
+		}
+		desired = append(desired, rule)
+		desiredNames[rule.Name] = true
+	}
+
+	// delete the rules removed from the configuration first to free their indexes
+	for name, id := range managed {
+		if desiredNames[name] {
+			continue
+		}
+		if current[id] != nil {
+			err := c.c.DeleteFirewallRule(ctx, site, id)
+			if _, ok := err.(*unifi.NotFoundError); !ok && err != nil {
+				return diag.FromErr(err)
+			}
+			delete(current, id)
+		}
+		delete(ids, name)
+	}
+
+	// indexes in use by other rules of the ruleset are skipped
+	managedIDs := map[string]bool{}
+	for _, rule := range desired {
+		if rule.ID != "" {
+			managedIDs[rule.ID] = true
+		}
+	}
+	used := map[int]bool{}
+	reserved := map[int]bool{}
+	for id, rule := range current {
+		if rule.Ruleset != ruleset {
+			continue
+		}
+		used[rule.RuleIndex] = true
+		if !managedIDs[id] {
+			reserved[rule.RuleIndex] = true
+		}
+	}
+
+	last := base + firewallRuleIndexRangeSize - 1
+	index := base
+	for _, rule := range desired {
+		for reserved[index] {
+			index++
+		}
+		if index > last {
+			return diag.Errorf("no free rule index left in %s between %d and %d", ruleset, base, last)
+		}
+		rule.RuleIndex = index
+		used[index] = true
+		index++
+	}
+
+	temp := last
+	for _, rule := range desired {
+		if rule.ID == "" || current[rule.ID].RuleIndex == rule.RuleIndex {
+			continue
+		}
+		for used[temp] {
+			temp--
+		}
+		if temp < base {
+			return diag.Errorf("no free temporary rule index left in %s between %d and %d", ruleset, base, last)
+		}
+
+		moved := *rule
+		moved.RuleIndex = temp
+		if _, err := c.c.UpdateFirewallRule(ctx, site, &moved); err != nil {
+			return diag.FromErr(fmt.Errorf("unable to move firewall rule %q: %w", rule.Name, err))
+		}
+		used[temp] = true
+	}
+
+	for _, rule := range desired {
+		var (
+			resp *unifi.FirewallRule
+			err  error
+		)
+		if rule.ID == "" {
+			resp, err = c.c.CreateFirewallRule(ctx, site, rule)
+		} else {
+			resp, err = c.c.UpdateFirewallRule(ctx, site, rule)
+		}
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("unable to apply firewall rule %q: %w", rule.Name, err))
+		}
+		ids[rule.Name] = resp.ID
+	}
+
+	d.Set("site", site)
+
+	return nil
+}
+
+// resourceFirewallRulesetGetRule builds a rule from a rule block, through the
+// unifi_firewall_rule resource.
+func resourceFirewallRulesetGetRule(block map[string]interface{}) (*unifi.FirewallRule, error) {
+	rd := resourceFirewallRule().Data(nil)
+	for k, v := range block {
+		if err := rd.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+	return resourceFirewallRuleGetResourceData(rd)
+}
+
+func resourceFirewallRulesetRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client)
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+
+	all, err := c.c.ListFirewallRule(ctx, site)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	byID := map[string]*unifi.FirewallRule{}
+	for i := range all {
+		byID[all[i].ID] = &all[i]
+	}
+
+	// rules deleted outside of Terraform are dropped, so they are created again
+	managedIDs := d.Get("rule_ids").(map[string]interface{})
+	rules := []*unifi.FirewallRule{}
+	for _, id := range managedIDs {
+		if rule, ok := byID[id.(string)]; ok {
+			rules = append(rules, rule)
+		}
+	}
+	if len(managedIDs) > 0 && len(rules) == 0 {
+		d.SetId("")
+		return nil
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].RuleIndex < rules[j].RuleIndex
+	})
+
+	ruleSchema := resourceFirewallRulesetRuleSchema()
+	blocks := make([]interface{}, 0, len(rules))
+	ids := map[string]string{}
+	for _, rule := range rules {
+		rd := resourceFirewallRule().Data(nil)
+		if err := resourceFirewallRuleSetResourceData(rule, rd, site); err != nil {
+			return diag.FromErr(err)
+		}
+		block := map[string]interface{}{}
+		for k := range ruleSchema {
+			block[k] = rd.Get(k)
+		}
+		blocks = append(blocks, block)
+		ids[rule.Name] = rule.ID
+	}
+
+	d.Set("site", site)
+	d.Set("rule", blocks)
+	d.Set("rule_ids", ids)
+
+	return nil
+}
+
+func resourceFirewallRulesetDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client)
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+
+	for _, id := range d.Get("rule_ids").(map[string]interface{}) {
+		err := c.c.DeleteFirewallRule(ctx, site, id.(string))
+		if _, ok := err.(*unifi.NotFoundError); !ok && err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/paultyng/go-unifi/unifi"
+)
+
+func TestAccFirewallRuleset_basic(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { preCheck(t) },
+		ProviderFactories: providerFactories,
+		// TODO: CheckDestroy: ,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFirewallRulesetConfig("first", "second"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("unifi_firewall_ruleset.test", "rule.#", "2"),
+					resource.TestCheckResourceAttr("unifi_firewall_ruleset.test", "rule_ids.%", "2"),
+				),
+			},
+			{
+				Config: testAccFirewallRulesetConfig("second", "first", "third"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("unifi_firewall_ruleset.test", "rule.0.name", "tfacc second"),
+					resource.TestCheckResourceAttr("unifi_firewall_ruleset.test", "rule_ids.%", "3"),
+				),
+			},
+		},
+	})
+}
+
+func testAccFirewallRulesetConfig(names ...string) string {
+	rules := ""
+	for _, name := range names {
+		rules += fmt.Sprintf(`
+	rule {
+		name        = "tfacc %s"
+		action      = "drop"
+		protocol    = "all"
+		dst_address = "192.168.1.1"
+	}
+`, name)
+	}
+
+	return fmt.Sprintf(`
+resource "unifi_firewall_ruleset" "test" {
+	ruleset         = "LAN_IN"
+	rule_index_base = 4000
+%s
+}
+`, rules)
+}
+
+func TestFirewallRuleset_fake(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	// a rule managed outside of the ruleset keeps its index
+	unmanagedID, err := s.AddObject("default", "firewallrule", map[string]interface{}{
+		"name":       "unmanaged",
+		"ruleset":    "LAN_IN",
+		"rule_index": 2001,
+		"action":     "accept",
+		"protocol":   "all",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rule := func(name, action string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":        name,
+			"action":      action,
+			"protocol":    "all",
+			"dst_address": "192.168.1.1",
+		}
+	}
+	indexes := func(t *testing.T) map[string]string {
+		m := map[string]string{}
+		for _, o := range s.Objects("default", "firewallrule") {
+			m[fmt.Sprint(o["name"])] = fmt.Sprint(o["rule_index"])
+		}
+		return m
+	}
+
+	var ids map[string]string
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_firewall_ruleset",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"ruleset": "LAN_IN",
+					"rule":    []interface{}{rule("a", "drop"), rule("b", "drop"), rule("c", "drop")},
+				},
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					expected := map[string]string{"unmanaged": "2001", "a": "2000", "b": "2002", "c": "2003"}
+					if actual := indexes(t); fmt.Sprint(actual) != fmt.Sprint(expected) {
+						t.Fatalf("expected indexes %v, got %v", expected, actual)
+					}
+					ids = map[string]string{}
+					for _, name := range []string{"a", "b", "c"} {
+						ids[name] = state.Attributes["rule_ids."+name]
+					}
+
+					r := New("test")().ResourcesMap["unifi_firewall_ruleset"]
+					for _, tc := range []struct {
+						name     string
+						rules    []interface{}
+						computed bool
+					}{
+						{"action changed", []interface{}{rule("a", "accept"), rule("b", "drop"), rule("c", "drop")}, false},
+						{"reordered", []interface{}{rule("c", "drop"), rule("a", "drop"), rule("b", "drop")}, false},
+						{"renamed", []interface{}{rule("a", "drop"), rule("b", "drop"), rule("e", "drop")}, true},
+						{"added", []interface{}{rule("a", "drop"), rule("b", "drop"), rule("c", "drop"), rule("d", "drop")}, true},
+						{"removed", []interface{}{rule("a", "drop"), rule("b", "drop")}, true},
+					} {
+						diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+							"ruleset": "LAN_IN",
+							"rule":    tc.rules,
+						}), c)
+						if err != nil {
+							t.Fatalf("%s: %s", tc.name, err)
+						}
+						computed := diff.Attributes["rule_ids.%"] != nil && diff.Attributes["rule_ids.%"].NewComputed
+						if computed != tc.computed {
+							t.Errorf("%s: expected rule_ids computed to be %t, got %t", tc.name, tc.computed, computed)
+						}
+					}
+				},
+			},
+			{
+				config: map[string]interface{}{
+					"ruleset": "LAN_IN",
+					"rule":    []interface{}{rule("c", "accept"), rule("d", "drop"), rule("a", "drop")},
+				},
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					expected := map[string]string{"unmanaged": "2001", "c": "2000", "d": "2002", "a": "2003"}
+					if actual := indexes(t); fmt.Sprint(actual) != fmt.Sprint(expected) {
+						t.Fatalf("expected indexes %v, got %v", expected, actual)
+					}
+					// reordered rules are updated in place
+					fakeCheckAttr("rule_ids.a", ids["a"])(t, state)
+					fakeCheckAttr("rule_ids.c", ids["c"])(t, state)
+					fakeCheckAttr("rule.0.action", "accept")(t, state)
+					if s.Object("default", "firewallrule", ids["b"]) != nil {
+						t.Fatal("expected the removed rule to be deleted")
+					}
+				},
+			},
+			{
+				config: map[string]interface{}{
+					"ruleset":         "LAN_IN",
+					"rule_index_base": 4000,
+					"rule":            []interface{}{rule("a", "drop"), rule("c", "accept")},
+				},
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					expected := map[string]string{"unmanaged": "2001", "a": "4000", "c": "4001"}
+					if actual := indexes(t); fmt.Sprint(actual) != fmt.Sprint(expected) {
+						t.Fatalf("expected indexes %v, got %v", expected, actual)
+					}
+				},
+				importStateVerify: true,
+				importStateID:     "default:LAN_IN/4000",
+			},
+		},
+	})
+
+	if objects := s.Objects("default", "firewallrule"); len(objects) != 1 || objects[0]["_id"] != unmanagedID {
+		t.Fatalf("expected only the unmanaged rule to be left, got %v", objects)
+	}
+
+	r := New("test")().ResourcesMap["unifi_firewall_ruleset"]
+	for _, tc := range []struct {
+		id            string
+		expectedError string
+	}{
+		{"LAN_IN", ""},
+		{"default:LAN_IN/4000", "no rules found in LAN_IN from rule index 4000"},
+		{"LAN_IN/3000", "rule index base must be 2000 or 4000"},
+		{"LAN", `expected ruleset to be one of`},
+	} {
+		imported, err := r.Importer.StateContext(context.Background(), r.Data(&terraform.InstanceState{ID: tc.id}), c)
+		if tc.expectedError != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Fatalf("import %q: expected error %q, got %v", tc.id, tc.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("import %q: %s", tc.id, err)
+		}
+		if actual := imported[0].Get("rule_ids").(map[string]interface{}); len(actual) != 1 || actual["unmanaged"] != unmanagedID {
+			t.Fatalf("import %q: expected the unmanaged rule, got %v", tc.id, actual)
+		}
+	}
+
+	err = testFakePlanError(t, c, "unifi_firewall_ruleset", map[string]interface{}{
+		"ruleset": "LAN_IN",
+		"rule":    []interface{}{rule("a", "drop"), rule("a", "accept")},
+	})
+	if !strings.Contains(err.Error(), `found "a" more than once`) {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+// firewallRuleFailClient fails the first delete of a firewall rule, and the
+// first create of the rule named failCreate.
+type firewallRuleFailClient struct {
+	unifiClient
+
+	deleted    bool
+	failCreate string
+}
+
+func (c *firewallRuleFailClient) DeleteFirewallRule(ctx context.Context, site, id string) error {
+	if !c.deleted {
+		c.deleted = true
+		return fmt.Errorf("delete failed")
+	}
+	return c.unifiClient.DeleteFirewallRule(ctx, site, id)
+}
+
+func (c *firewallRuleFailClient) CreateFirewallRule(ctx context.Context, site string, d *unifi.FirewallRule) (*unifi.FirewallRule, error) {
+	if d.Name == c.failCreate {
+		c.failCreate = ""
+		return nil, fmt.Errorf("create failed")
+	}
+	return c.unifiClient.CreateFirewallRule(ctx, site, d)
+}
+
+func TestFirewallRuleset_fake_partialApply(t *testing.T) {
+	c, s := newFakeClient(t)
+	c = &client{
+		c:    &firewallRuleFailClient{unifiClient: c.c, failCreate: "d"},
+		site: c.site,
+	}
+
+	rule := func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":     name,
+			"action":   "drop",
+			"protocol": "all",
+		}
+	}
+	names := func() []string {
+		names := []string{}
+		for _, o := range s.Objects("default", "firewallrule") {
+			names = append(names, fmt.Sprint(o["name"]))
+		}
+		sort.Strings(names)
+		return names
+	}
+	checkNames := func(expected ...string) func(*testing.T, *terraform.InstanceState) {
+		return func(t *testing.T, state *terraform.InstanceState) {
+			if actual := names(); fmt.Sprint(actual) != fmt.Sprint(expected) {
+				t.Fatalf("expected rules %v, got %v", expected, actual)
+			}
+		}
+	}
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_firewall_ruleset",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"ruleset": "LAN_IN",
+					"rule":    []interface{}{rule("a"), rule("b"), rule("c")},
+				},
+				check: checkNames("a", "b", "c"),
+			},
+			{
+				// the delete of b fails, b is still managed
+				config: map[string]interface{}{
+					"ruleset": "LAN_IN",
+					"rule":    []interface{}{rule("a"), rule("c")},
+				},
+				expectError: "delete failed",
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					fakeCheckAttr("rule_ids.%", "3")(t, state)
+				},
+			},
+			{
+				config: map[string]interface{}{
+					"ruleset": "LAN_IN",
+					"rule":    []interface{}{rule("a"), rule("c")},
+				},
+				check: checkNames("a", "c"),
+			},
+			{
+				// the create of d fails, e is never reached
+				config: map[string]interface{}{
+					"ruleset": "LAN_IN",
+					"rule":    []interface{}{rule("a"), rule("d"), rule("e"), rule("c")},
+				},
+				expectError: "create failed",
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					fakeCheckAttr("rule_ids.%", "2")(t, state)
+				},
+			},
+			{
+				config: map[string]interface{}{
+					"ruleset": "LAN_IN",
+					"rule":    []interface{}{rule("a"), rule("d"), rule("e"), rule("c")},
+				},
+				check: checkNames("a", "c", "d", "e"),
+			},
+		},
+	})
+}
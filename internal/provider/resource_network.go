@@ -3,9 +3,10 @@
 import (
 	"context"
 	"fmt"
+	"net"
 	"regexp"
-	"strings"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/paultyng/go-unifi/unifi"
@@ -29,12 +30,19 @@ func resourceNetwork() *schema.Resource {
 	return &schema.Resource{
 		Description: "`unifi_network` manages WAN/LAN/VLAN networks.",
 
-		Create: resourceNetworkCreate,
-		Read:   resourceNetworkRead,
-		Update: resourceNetworkUpdate,
-		Delete: resourceNetworkDelete,
+		CreateContext: resourceNetworkCreate,
+		ReadContext:   resourceNetworkRead,
+		UpdateContext: resourceNetworkUpdate,
+		DeleteContext: resourceNetworkDelete,
+		CustomizeDiff: resourceNetworkCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			State: importNetwork,
+			StateContext: importSiteAndName("network", resourceNetworkImportNames),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -81,13 +89,13 @@ func resourceNetwork() *schema.Resource {
 				Default:     "LAN",
 			},
 			"dhcp_start": {
-				Description:  "The IPv4 address where the DHCP range of addresses starts.",
+				Description:  "The IPv4 address where the DHCP range of addresses starts. Must be inside `subnet` and not the gateway.",
 				Type:         schema.TypeString,
 				Optional:     true,
 				ValidateFunc: validation.IsIPv4Address,
 			},
 			"dhcp_stop": {
-				Description:  "The IPv4 address where the DHCP range of addresses stops.",
+				Description:  "The IPv4 address where the DHCP range of addresses stops. Must be inside `subnet` and not before `dhcp_start`.",
 				Type:         schema.TypeString,
 				Optional:     true,
 				ValidateFunc: validation.IsIPv4Address,
@@ -151,9 +159,11 @@ func resourceNetwork() *schema.Resource {
 				Default:     "none",
 			},
 			"ipv6_static_subnet": {
-				Description: "Specifies the static IPv6 subnet when ipv6_interface_type is 'static'.",
-				Type:        schema.TypeString,
-				Optional:    true,
+				Description:      "Specifies the static IPv6 subnet when ipv6_interface_type is 'static'.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     ipv6CIDRValidate,
+				DiffSuppressFunc: ipDiffSuppress,
 			},
 			"ipv6_pd_interface": {
 				Description: "Specifies which WAN interface to use for IPv6 PD.",
@@ -232,12 +242,72 @@ func resourceNetwork() *schema.Resource {
 	}
 }
 
-func resourceNetworkCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceNetworkCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	for _, k := range []string{"subnet", "dhcp_start", "dhcp_stop"} {
+		if !d.NewValueKnown(k) {
+			return nil
+		}
+	}
+
+	return validateDHCPRange(d.Get("subnet").(string), d.Get("dhcp_start").(string), d.Get("dhcp_stop").(string))
+}
+
+// validateDHCPRange checks the DHCP range lies inside the subnet and does not
+// include the gateway, which is the first address of the subnet.
+func validateDHCPRange(subnet, start, stop string) error {
+	if subnet == "" {
+		return nil
+	}
+	_, subnetNet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		// already reported by the attribute validation
+		return nil
+	}
+	gateway, _, _ := net.ParseCIDR(cidrOneBased(subnet))
+	broadcast := ipv4Broadcast(subnetNet)
+
+	for _, bound := range []struct {
+		name  string
+		value string
+	}{
+		{"dhcp_start", start},
+		{"dhcp_stop", stop},
+	} {
+		ip := net.ParseIP(bound.value)
+		if ip == nil {
+			continue
+		}
+		if !subnetNet.Contains(ip) {
+			return fmt.Errorf("%s %s is not inside subnet %s", bound.name, bound.value, subnetNet)
+		}
+		if ip.Equal(subnetNet.IP) {
+			return fmt.Errorf("%s %s is the network address of subnet %s", bound.name, bound.value, subnetNet)
+		}
+		if ip.Equal(gateway) {
+			return fmt.Errorf("%s %s is the gateway address of subnet %s", bound.name, bound.value, subnetNet)
+		}
+		if broadcast != nil && ip.Equal(broadcast) {
+			return fmt.Errorf("%s %s is the broadcast address of subnet %s", bound.name, bound.value, subnetNet)
+		}
+	}
+
+	startIP, stopIP := net.ParseIP(start), net.ParseIP(stop)
+	if startIP == nil || stopIP == nil {
+		return nil
+	}
+	if ipCompare(startIP, stopIP) > 0 {
+		return fmt.Errorf("dhcp_start %s must not be after dhcp_stop %s", start, stop)
+	}
+
+	return nil
+}
+
+func resourceNetworkCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourceNetworkGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	site := d.Get("site").(string)
@@ -245,14 +315,14 @@ func resourceNetworkCreate(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	resp, err := c.c.CreateNetwork(context.TODO(), site, req)
+	resp, err := c.c.CreateNetwork(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(resp.ID)
 
-	return resourceNetworkSetResourceData(resp, d, site)
+	return diag.FromErr(resourceNetworkSetResourceData(resp, d, site))
 }
 
 func resourceNetworkGetResourceData(d *schema.ResourceData) (*unifi.Network, error) {
@@ -406,7 +476,7 @@ func resourceNetworkSetResourceData(resp *unifi.Network, d *schema.ResourceData,
 	return nil
 }
 
-func resourceNetworkRead(d *schema.ResourceData, meta interface{}) error {
+func resourceNetworkRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -416,24 +486,24 @@ func resourceNetworkRead(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	resp, err := c.c.GetNetwork(context.TODO(), site, id)
+	resp, err := c.c.GetNetwork(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		d.SetId("")
 		return nil
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceNetworkSetResourceData(resp, d, site)
+	return diag.FromErr(resourceNetworkSetResourceData(resp, d, site))
 }
 
-func resourceNetworkUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceNetworkUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourceNetworkGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	req.ID = d.Id()
@@ -443,15 +513,15 @@ func resourceNetworkUpdate(d *schema.ResourceData, meta interface{}) error {
 	}
 	req.SiteID = site
 
-	resp, err := c.c.UpdateNetwork(context.TODO(), site, req)
+	resp, err := c.c.UpdateNetwork(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceNetworkSetResourceData(resp, d, site)
+	return diag.FromErr(resourceNetworkSetResourceData(resp, d, site))
 }
 
-func resourceNetworkDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceNetworkDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	name := d.Get("name").(string)
@@ -461,65 +531,22 @@ func resourceNetworkDelete(d *schema.ResourceData, meta interface{}) error {
 	}
 	id := d.Id()
 
-	err := c.c.DeleteNetwork(context.TODO(), site, id, name)
+	err := c.c.DeleteNetwork(ctx, site, id, name)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		return nil
 	}
-	return err
+	return diag.FromErr(err)
 }
 
-func importNetwork(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	c := meta.(*client)
-	id := d.Id()
-	site := d.Get("site").(string)
-	if site == "" {
-		site = c.site
-	}
-
-	if strings.Contains(id, ":") {
-		importParts := strings.SplitN(id, ":", 2)
-		site = importParts[0]
-		id = importParts[1]
-	}
-
-	if strings.HasPrefix(id, "name=") {
-		targetName := strings.TrimPrefix(id, "name=")
-		var err error
-		if id, err = getNetworkIDByName(c.c, targetName, site); err != nil {
-			return nil, err
-		}
-	}
-
-	if id != "" {
-		d.SetId(id)
-	}
-	if site != "" {
-		d.Set("site", site)
-	}
-
-	return []*schema.ResourceData{d}, nil
-}
-
-func getNetworkIDByName(client unifiClient, networkName, site string) (string, error) {
-	networks, err := client.ListNetwork(context.TODO(), site)
+func resourceNetworkImportNames(ctx context.Context, c unifiClient, site string) ([]importName, error) {
+	networks, err := c.ListNetwork(ctx, site)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	idMatchingName := ""
-	allNames := []string{}
-	for _, network := range networks {
-		allNames = append(allNames, network.Name)
-		if network.Name != networkName {
-			continue
-		}
-		if idMatchingName != "" {
-			return "", fmt.Errorf("Found multiple networks with name '%s'", networkName)
-		}
-		idMatchingName = network.ID
-	}
-	if idMatchingName == "" {
-		return "", fmt.Errorf("Found no networks with name '%s', found: %s", networkName, strings.Join(allNames, ", "))
+	names := make([]importName, 0, len(networks))
+	for _, n := range networks {
+		names = append(names, importName{id: n.ID, name: n.Name})
 	}
-	return idMatchingName, nil
+	return names, nil
 }
@@ -8,6 +8,7 @@
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccNetwork_basic(t *testing.T) {
@@ -438,3 +439,97 @@ func testAccNetworkWithDuplicateNames(vlan1, vlan2 int, networkName string) stri
 }
 `, vlan1, vlan2, networkName)
 }
+
+func TestNetwork_fake(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	config := func(vlan int, igmpSnoop bool, dhcpDNS ...interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"name":          "tfacc",
+			"purpose":       "corporate",
+			"subnet":        "10.0.202.0/24",
+			"vlan_id":       vlan,
+			"dhcp_start":    "10.0.202.6",
+			"dhcp_stop":     "10.0.202.254",
+			"dhcp_enabled":  true,
+			"domain_name":   "foo.local",
+			"igmp_snooping": igmpSnoop,
+			"dhcp_dns":      dhcpDNS,
+		}
+	}
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_network",
+		steps: []fakeStep{
+			{
+				config: config(202, true),
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					// the controller expects the gateway address in the subnet
+					if actual := s.Object("default", "networkconf", state.ID)["ip_subnet"]; actual != "10.0.202.1/24" {
+						t.Fatalf("expected ip_subnet %q, got %q", "10.0.202.1/24", actual)
+					}
+				},
+				importStateVerify: true,
+			},
+			{
+				config:            config(203, false, "192.168.1.101", "192.168.1.102"),
+				check:             fakeCheckAttr("dhcp_dns.1", "192.168.1.102"),
+				importStateVerify: true,
+				importStateID:     "default:name=tfacc",
+			},
+		},
+	})
+}
+
+func TestValidateDHCPRange(t *testing.T) {
+	for _, c := range []struct {
+		name          string
+		subnet        string
+		start         string
+		stop          string
+		expectedError string
+	}{
+		{"valid", "10.0.0.0/24", "10.0.0.6", "10.0.0.254", ""},
+		{"single address", "10.0.0.0/24", "10.0.0.6", "10.0.0.6", ""},
+		{"no subnet", "", "10.0.0.6", "10.0.0.254", ""},
+		{"no range", "10.0.0.0/24", "", "", ""},
+		{"start outside", "10.0.0.0/24", "10.0.1.6", "10.0.0.254", "dhcp_start 10.0.1.6 is not inside subnet 10.0.0.0/24"},
+		{"stop outside", "10.0.0.0/24", "10.0.0.6", "10.0.1.254", "dhcp_stop 10.0.1.254 is not inside subnet 10.0.0.0/24"},
+		{"start gateway", "10.0.0.0/24", "10.0.0.1", "10.0.0.254", "dhcp_start 10.0.0.1 is the gateway address"},
+		{"start network address", "10.0.0.128/25", "10.0.0.128", "10.0.0.200", "dhcp_start 10.0.0.128 is the network address of subnet 10.0.0.128/25"},
+		{"stop network address", "10.0.0.0/24", "10.0.0.6", "10.0.0.0", "dhcp_stop 10.0.0.0 is the network address of subnet 10.0.0.0/24"},
+		{"start broadcast address", "10.0.0.0/24", "10.0.0.255", "10.0.0.255", "dhcp_start 10.0.0.255 is the broadcast address of subnet 10.0.0.0/24"},
+		{"stop broadcast address", "10.0.0.128/25", "10.0.0.130", "10.0.0.255", "dhcp_stop 10.0.0.255 is the broadcast address of subnet 10.0.0.128/25"},
+		{"host bits in subnet", "10.0.0.1/24", "10.0.0.6", "10.0.0.254", ""},
+		{"reversed", "10.0.0.0/24", "10.0.0.200", "10.0.0.100", "dhcp_start 10.0.0.200 must not be after dhcp_stop 10.0.0.100"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateDHCPRange(c.subnet, c.start, c.stop)
+			if c.expectedError == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %q", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+				t.Fatalf("expected error %q, got %v", c.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestNetwork_fake_invalidDHCPRange(t *testing.T) {
+	c, _ := newFakeClient(t)
+
+	err := testFakePlanError(t, c, "unifi_network", map[string]interface{}{
+		"name":         "tfacc",
+		"purpose":      "corporate",
+		"subnet":       "10.0.202.0/24",
+		"dhcp_start":   "10.0.202.6",
+		"dhcp_stop":    "10.0.203.254",
+		"dhcp_enabled": true,
+	})
+	if !strings.Contains(err.Error(), "dhcp_stop 10.0.203.254 is not inside subnet 10.0.202.0/24") {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
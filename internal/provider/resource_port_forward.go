@@ -3,6 +3,7 @@
 import (
 	"context"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/paultyng/go-unifi/unifi"
@@ -12,12 +13,18 @@ func resourcePortForward() *schema.Resource {
 	return &schema.Resource{
 		Description: "`unifi_port_forward` manages a port forwarding rule on the gateway.",
 
-		Create: resourcePortForwardCreate,
-		Read:   resourcePortForwardRead,
-		Update: resourcePortForwardUpdate,
-		Delete: resourcePortForwardDelete,
+		CreateContext: resourcePortForwardCreate,
+		ReadContext:   resourcePortForwardRead,
+		UpdateContext: resourcePortForwardUpdate,
+		DeleteContext: resourcePortForwardDelete,
 		Importer: &schema.ResourceImporter{
-			State: importSiteAndID,
+			StateContext: importSiteAndName("port forward", resourcePortForwardImportNames),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -98,26 +105,26 @@ func resourcePortForward() *schema.Resource {
 	}
 }
 
-func resourcePortForwardCreate(d *schema.ResourceData, meta interface{}) error {
+func resourcePortForwardCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourcePortForwardGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	site := d.Get("site").(string)
 	if site == "" {
 		site = c.site
 	}
-	resp, err := c.c.CreatePortForward(context.TODO(), site, req)
+	resp, err := c.c.CreatePortForward(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(resp.ID)
 
-	return resourcePortForwardSetResourceData(resp, d, site)
+	return diag.FromErr(resourcePortForwardSetResourceData(resp, d, site))
 }
 
 func resourcePortForwardGetResourceData(d *schema.ResourceData) (*unifi.PortForward, error) {
@@ -149,7 +156,7 @@ func resourcePortForwardSetResourceData(resp *unifi.PortForward, d *schema.Resou
 	return nil
 }
 
-func resourcePortForwardRead(d *schema.ResourceData, meta interface{}) error {
+func resourcePortForwardRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -158,24 +165,24 @@ func resourcePortForwardRead(d *schema.ResourceData, meta interface{}) error {
 	if site == "" {
 		site = c.site
 	}
-	resp, err := c.c.GetPortForward(context.TODO(), site, id)
+	resp, err := c.c.GetPortForward(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		d.SetId("")
 		return nil
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourcePortForwardSetResourceData(resp, d, site)
+	return diag.FromErr(resourcePortForwardSetResourceData(resp, d, site))
 }
 
-func resourcePortForwardUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourcePortForwardUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourcePortForwardGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	req.ID = d.Id()
@@ -186,15 +193,15 @@ func resourcePortForwardUpdate(d *schema.ResourceData, meta interface{}) error {
 	}
 	req.SiteID = site
 
-	resp, err := c.c.UpdatePortForward(context.TODO(), site, req)
+	resp, err := c.c.UpdatePortForward(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourcePortForwardSetResourceData(resp, d, site)
+	return diag.FromErr(resourcePortForwardSetResourceData(resp, d, site))
 }
 
-func resourcePortForwardDelete(d *schema.ResourceData, meta interface{}) error {
+func resourcePortForwardDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -204,6 +211,19 @@ func resourcePortForwardDelete(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	err := c.c.DeletePortForward(context.TODO(), site, id)
-	return err
+	err := c.c.DeletePortForward(ctx, site, id)
+	return diag.FromErr(err)
+}
+
+func resourcePortForwardImportNames(ctx context.Context, c unifiClient, site string) ([]importName, error) {
+	forwards, err := c.ListPortForward(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]importName, 0, len(forwards))
+	for _, o := range forwards {
+		names = append(names, importName{id: o.ID, name: o.Name})
+	}
+	return names, nil
 }
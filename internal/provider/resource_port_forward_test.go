@@ -100,3 +100,36 @@ func testAccPortForwardConfigSrc(dstPort string, enabled bool, fwdIP, fwdPort, n
 }
 `, dstPort, enabled, fwdIP, fwdPort, name, src)
 }
+
+func TestPortForward_fake(t *testing.T) {
+	c, _ := newFakeClient(t)
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_port_forward",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"dst_port": "22",
+					"enabled":  true,
+					"fwd_ip":   "10.1.1.1",
+					"fwd_port": "22",
+					"name":     "ssh",
+				},
+				importStateVerify: true,
+			},
+			{
+				config: map[string]interface{}{
+					"dst_port": "8022",
+					"enabled":  false,
+					"fwd_ip":   "10.1.1.2",
+					"fwd_port": "22",
+					"name":     "ssh",
+					"src_ip":   "192.168.0.1",
+				},
+				check:             fakeCheckAttr("src_ip", "192.168.0.1"),
+				importStateVerify: true,
+				importStateID:     "name=ssh",
+			},
+		},
+	})
+}
@@ -3,6 +3,7 @@
 import (
 	"context"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/paultyng/go-unifi/unifi"
@@ -12,12 +13,18 @@ func resourcePortProfile() *schema.Resource {
 	return &schema.Resource{
 		Description: "`unifi_port_profile` manages a port profile for use on network switches.",
 
-		Create: resourcePortProfileCreate,
-		Read:   resourcePortProfileRead,
-		Update: resourcePortProfileUpdate,
-		Delete: resourcePortProfileDelete,
+		CreateContext: resourcePortProfileCreate,
+		ReadContext:   resourcePortProfileRead,
+		UpdateContext: resourcePortProfileUpdate,
+		DeleteContext: resourcePortProfileDelete,
 		Importer: &schema.ResourceImporter{
-			State: importSiteAndID,
+			StateContext: importSiteAndName("port profile", resourcePortProfileImportNames),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -248,26 +255,26 @@ func resourcePortProfile() *schema.Resource {
 	}
 }
 
-func resourcePortProfileCreate(d *schema.ResourceData, meta interface{}) error {
+func resourcePortProfileCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourcePortProfileGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	site := d.Get("site").(string)
 	if site == "" {
 		site = c.site
 	}
-	resp, err := c.c.CreatePortProfile(context.TODO(), site, req)
+	resp, err := c.c.CreatePortProfile(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(resp.ID)
 
-	return resourcePortProfileSetResourceData(resp, d, site)
+	return diag.FromErr(resourcePortProfileSetResourceData(resp, d, site))
 }
 
 func resourcePortProfileGetResourceData(d *schema.ResourceData) (*unifi.PortProfile, error) {
@@ -359,7 +366,7 @@ func resourcePortProfileSetResourceData(resp *unifi.PortProfile, d *schema.Resou
 	return nil
 }
 
-func resourcePortProfileRead(d *schema.ResourceData, meta interface{}) error {
+func resourcePortProfileRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -368,24 +375,24 @@ func resourcePortProfileRead(d *schema.ResourceData, meta interface{}) error {
 	if site == "" {
 		site = c.site
 	}
-	resp, err := c.c.GetPortProfile(context.TODO(), site, id)
+	resp, err := c.c.GetPortProfile(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		d.SetId("")
 		return nil
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourcePortProfileSetResourceData(resp, d, site)
+	return diag.FromErr(resourcePortProfileSetResourceData(resp, d, site))
 }
 
-func resourcePortProfileUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourcePortProfileUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourcePortProfileGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	req.ID = d.Id()
@@ -396,15 +403,15 @@ func resourcePortProfileUpdate(d *schema.ResourceData, meta interface{}) error {
 	}
 	req.SiteID = site
 
-	resp, err := c.c.UpdatePortProfile(context.TODO(), site, req)
+	resp, err := c.c.UpdatePortProfile(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourcePortProfileSetResourceData(resp, d, site)
+	return diag.FromErr(resourcePortProfileSetResourceData(resp, d, site))
 }
 
-func resourcePortProfileDelete(d *schema.ResourceData, meta interface{}) error {
+func resourcePortProfileDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -414,6 +421,19 @@ func resourcePortProfileDelete(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	err := c.c.DeletePortProfile(context.TODO(), site, id)
-	return err
+	err := c.c.DeletePortProfile(ctx, site, id)
+	return diag.FromErr(err)
+}
+
+func resourcePortProfileImportNames(ctx context.Context, c unifiClient, site string) ([]importName, error) {
+	profiles, err := c.ListPortProfile(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]importName, 0, len(profiles))
+	for _, o := range profiles {
+		names = append(names, importName{id: o.ID, name: o.Name})
+	}
+	return names, nil
 }
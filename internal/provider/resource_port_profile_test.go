@@ -32,3 +32,36 @@ func TestAccPortProfile_basic(t *testing.T) {
 	stp_port_mode = false
 }
 `
+
+func TestPortProfile_fake(t *testing.T) {
+	c, _ := newFakeClient(t)
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_port_profile",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"name":          "provider created",
+					"poe_mode":      "off",
+					"speed":         1000,
+					"stp_port_mode": false,
+				},
+				check:             fakeCheckAttr("speed", "1000"),
+				importStateVerify: true,
+				importStateID:     "default:name=provider created",
+			},
+			{
+				config: map[string]interface{}{
+					"name":                  "provider created",
+					"poe_mode":              "off",
+					"speed":                 1000,
+					"stp_port_mode":         false,
+					"stormctrl_bcast_level": 50,
+					"stormctrl_bcast_rate":  1000,
+				},
+				expectError: `"stormctrl_bcast_level": conflicts with stormctrl_bcast_rate`,
+				check:       fakeCheckAttr("stormctrl_bcast_level", "0"),
+			},
+		},
+	})
+}
@@ -0,0 +1,324 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/paultyng/go-unifi/unifi"
+)
+
+func resourceRADIUSProfile() *schema.Resource {
+	return &schema.Resource{
+		Description: "`unifi_radius_profile` manages RADIUS profiles, which can be used by WLANs with `wpaeap` " +
+			"security and by 802.1X port profiles.",
+
+		CreateContext: resourceRADIUSProfileCreate,
+		ReadContext:   resourceRADIUSProfileRead,
+		UpdateContext: resourceRADIUSProfileUpdate,
+		DeleteContext: resourceRADIUSProfileDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importSiteAndID,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Description: "The ID of the RADIUS profile.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"site": {
+				Description: "The name of the site to associate the RADIUS profile with.",
+				Type:        schema.TypeString,
+				Computed:    true,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Description:  "The name of the RADIUS profile.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringLenBetween(1, 128),
+			},
+			"auth_server": {
+				Description: "RADIUS authentication servers.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        resourceRADIUSProfileServer(1812),
+			},
+			"acct_server": {
+				Description: "RADIUS accounting servers.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        resourceRADIUSProfileServer(1813),
+			},
+			"accounting_enabled": {
+				Description: "Specifies whether RADIUS accounting is enabled.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+			},
+			"interim_update_enabled": {
+				Description: "Specifies whether interim accounting updates are sent.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+			},
+			"interim_update_interval": {
+				Description:  "Interval in seconds between interim accounting updates.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3600,
+				ValidateFunc: validation.IntBetween(60, 86400),
+			},
+			"use_usg_auth_server": {
+				Description: "Use the gateway's built-in RADIUS server for authentication.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+			},
+			"use_usg_acct_server": {
+				Description: "Use the gateway's built-in RADIUS server for accounting.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+			},
+			"vlan_enabled": {
+				Description: "Specifies whether RADIUS assigned VLANs are enabled for wired clients.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+			},
+			"vlan_wlan_mode": {
+				Description:  "RADIUS assigned VLAN mode for wireless clients. Valid values are `disabled`, `optional` and `required`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "disabled",
+				ValidateFunc: validation.StringInSlice([]string{"disabled", "optional", "required"}, false),
+			},
+		},
+	}
+}
+
+func resourceRADIUSProfileServer(defaultPort int) *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"ip": {
+				Description:  "IP address of the RADIUS server.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsIPv4Address,
+			},
+			"port": {
+				Description:  "Port of the RADIUS server.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultPort,
+				ValidateFunc: validation.IsPortNumber,
+			},
+			"secret": {
+				Description: "Shared secret for the RADIUS server.",
+				Type:        schema.TypeString,
+				Required:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+func resourceRADIUSProfileCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client)
+
+	req, err := resourceRADIUSProfileGetResourceData(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+
+	resp, err := c.c.CreateRADIUSProfile(ctx, site, req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(resp.ID)
+
+	return diag.FromErr(resourceRADIUSProfileSetResourceData(resp, d, site))
+}
+
+func resourceRADIUSProfileGetResourceData(d *schema.ResourceData) (*unifi.RADIUSProfile, error) {
+	authServers, err := listToRADIUSProfileAuthServers(d.Get("auth_server").([]interface{}))
+	if err != nil {
+		return nil, fmt.Errorf("unable to process auth_server block: %w", err)
+	}
+
+	acctServers, err := listToRADIUSProfileAcctServers(d.Get("acct_server").([]interface{}))
+	if err != nil {
+		return nil, fmt.Errorf("unable to process acct_server block: %w", err)
+	}
+
+	return &unifi.RADIUSProfile{
+		Name: d.Get("name").(string),
+
+		AuthServers: authServers,
+		AcctServers: acctServers,
+
+		AccountingEnabled:     d.Get("accounting_enabled").(bool),
+		InterimUpdateEnabled:  d.Get("interim_update_enabled").(bool),
+		InterimUpdateInterval: d.Get("interim_update_interval").(int),
+		UseUsgAuthServer:      d.Get("use_usg_auth_server").(bool),
+		UseUsgAcctServer:      d.Get("use_usg_acct_server").(bool),
+		VLANEnabled:           d.Get("vlan_enabled").(bool),
+		VLANWLANMode:          d.Get("vlan_wlan_mode").(string),
+	}, nil
+}
+
+func resourceRADIUSProfileSetResourceData(resp *unifi.RADIUSProfile, d *schema.ResourceData, site string) error {
+	interimUpdateInterval := resp.InterimUpdateInterval
+	if interimUpdateInterval == 0 {
+		interimUpdateInterval = 3600
+	}
+
+	vlanWLANMode := resp.VLANWLANMode
+	if vlanWLANMode == "" {
+		vlanWLANMode = "disabled"
+	}
+
+	d.Set("site", site)
+	d.Set("name", resp.Name)
+	d.Set("auth_server", listFromRADIUSProfileAuthServers(resp.AuthServers))
+	d.Set("acct_server", listFromRADIUSProfileAcctServers(resp.AcctServers))
+	d.Set("accounting_enabled", resp.AccountingEnabled)
+	d.Set("interim_update_enabled", resp.InterimUpdateEnabled)
+	d.Set("interim_update_interval", interimUpdateInterval)
+	d.Set("use_usg_auth_server", resp.UseUsgAuthServer)
+	d.Set("use_usg_acct_server", resp.UseUsgAcctServer)
+	d.Set("vlan_enabled", resp.VLANEnabled)
+	d.Set("vlan_wlan_mode", vlanWLANMode)
+
+	return nil
+}
+
+func resourceRADIUSProfileRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client)
+
+	id := d.Id()
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+
+	resp, err := c.c.GetRADIUSProfile(ctx, site, id)
+	if _, ok := err.(*unifi.NotFoundError); ok {
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.FromErr(resourceRADIUSProfileSetResourceData(resp, d, site))
+}
+
+func resourceRADIUSProfileUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client)
+
+	req, err := resourceRADIUSProfileGetResourceData(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	req.ID = d.Id()
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+	req.SiteID = site
+
+	resp, err := c.c.UpdateRADIUSProfile(ctx, site, req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.FromErr(resourceRADIUSProfileSetResourceData(resp, d, site))
+}
+
+func resourceRADIUSProfileDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client)
+
+	id := d.Id()
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+	err := c.c.DeleteRADIUSProfile(ctx, site, id)
+	if _, ok := err.(*unifi.NotFoundError); ok {
+		return nil
+	}
+	return diag.FromErr(err)
+}
+
+func listToRADIUSProfileAuthServers(list []interface{}) ([]unifi.RADIUSProfileAuthServers, error) {
+	servers := make([]unifi.RADIUSProfileAuthServers, 0, len(list))
+	for _, item := range list {
+		data, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected data in block")
+		}
+		servers = append(servers, unifi.RADIUSProfileAuthServers{
+			IP:      data["ip"].(string),
+			Port:    data["port"].(int),
+			XSecret: data["secret"].(string),
+		})
+	}
+	return servers, nil
+}
+
+func listToRADIUSProfileAcctServers(list []interface{}) ([]unifi.RADIUSProfileAcctServers, error) {
+	servers := make([]unifi.RADIUSProfileAcctServers, 0, len(list))
+	for _, item := range list {
+		data, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected data in block")
+		}
+		servers = append(servers, unifi.RADIUSProfileAcctServers{
+			IP:      data["ip"].(string),
+			Port:    data["port"].(int),
+			XSecret: data["secret"].(string),
+		})
+	}
+	return servers, nil
+}
+
+func listFromRADIUSProfileAuthServers(servers []unifi.RADIUSProfileAuthServers) []interface{} {
+	list := make([]interface{}, 0, len(servers))
+	for _, s := range servers {
+		list = append(list, map[string]interface{}{
+			"ip":     s.IP,
+			"port":   s.Port,
+			"secret": s.XSecret,
+		})
+	}
+	return list
+}
+
+func listFromRADIUSProfileAcctServers(servers []unifi.RADIUSProfileAcctServers) []interface{} {
+	list := make([]interface{}, 0, len(servers))
+	for _, s := range servers {
+		list = append(list, map[string]interface{}{
+			"ip":     s.IP,
+			"port":   s.Port,
+			"secret": s.XSecret,
+		})
+	}
+	return list
+}
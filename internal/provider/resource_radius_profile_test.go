@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccRADIUSProfile_basic(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { preCheck(t) },
+		ProviderFactories: providerFactories,
+		// TODO: CheckDestroy: ,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRADIUSProfileConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("unifi_radius_profile.test", "auth_server.0.port", "1812"),
+				),
+			},
+			importStep("unifi_radius_profile.test"),
+			{
+				Config: testAccRADIUSProfileConfig_accounting,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("unifi_radius_profile.test", "acct_server.0.port", "1813"),
+					resource.TestCheckResourceAttr("unifi_radius_profile.test", "interim_update_interval", "600"),
+				),
+			},
+			importStep("unifi_radius_profile.test"),
+			{
+				Config: testAccRADIUSProfileConfig,
+			},
+			importStep("unifi_radius_profile.test"),
+		},
+	})
+}
+
+const testAccRADIUSProfileConfig = `
+resource "unifi_radius_profile" "test" {
+	name = "tfacc"
+
+	auth_server {
+		ip     = "192.168.1.10"
+		secret = "tfacc-secret"
+	}
+}
+`
+
+const testAccRADIUSProfileConfig_accounting = `
+resource "unifi_radius_profile" "test" {
+	name = "tfacc"
+
+	auth_server {
+		ip     = "192.168.1.10"
+		secret = "tfacc-secret"
+	}
+
+	accounting_enabled = true
+
+	acct_server {
+		ip     = "192.168.1.10"
+		secret = "tfacc-secret"
+	}
+
+	interim_update_enabled  = true
+	interim_update_interval = 600
+
+	vlan_enabled   = true
+	vlan_wlan_mode = "optional"
+}
+`
+
+func TestRADIUSProfile_fake(t *testing.T) {
+	c, _ := newFakeClient(t)
+
+	authServer := []interface{}{
+		map[string]interface{}{
+			"ip":     "192.168.1.10",
+			"secret": "tfacc-secret",
+		},
+	}
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_radius_profile",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"name":        "tfacc",
+					"auth_server": authServer,
+				},
+				check:             fakeCheckAttr("auth_server.0.port", "1812"),
+				importStateVerify: true,
+			},
+			{
+				config: map[string]interface{}{
+					"name":               "tfacc",
+					"auth_server":        authServer,
+					"accounting_enabled": true,
+					"acct_server": []interface{}{
+						map[string]interface{}{
+							"ip":     "192.168.1.11",
+							"secret": "tfacc-secret",
+						},
+					},
+					"interim_update_enabled":  true,
+					"interim_update_interval": 600,
+				},
+				check:             fakeCheckAttr("acct_server.0.port", "1813"),
+				importStateVerify: true,
+			},
+		},
+	})
+}
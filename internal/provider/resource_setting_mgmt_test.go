@@ -57,3 +57,27 @@ func testAccSettingMgmtConfig_site() string {
 }
 `
 }
+
+func TestSettingMgmt_fake(t *testing.T) {
+	c, _ := newFakeClient(t)
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_setting_mgmt",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"auto_upgrade": true,
+				},
+				check: fakeCheckAttr("auto_upgrade", "true"),
+			},
+			{
+				config: map[string]interface{}{
+					"auto_upgrade": false,
+				},
+				check: fakeCheckAttr("auto_upgrade", "false"),
+			},
+		},
+		// settings always exist on the controller
+		skipCheckDestroy: true,
+	})
+}
@@ -72,3 +72,26 @@ func testAccSiteConfig(desc string) string {
 }
 `, desc)
 }
+
+func TestSite_fake(t *testing.T) {
+	c, _ := newFakeClient(t)
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_site",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"description": "tfacc",
+				},
+				importStateVerify: true,
+			},
+			{
+				config: map[string]interface{}{
+					"description": "tfacc updated",
+				},
+				check:             fakeCheckAttr("description", "tfacc updated"),
+				importStateVerify: true,
+			},
+		},
+	})
+}
@@ -4,6 +4,7 @@
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/paultyng/go-unifi/unifi"
@@ -13,12 +14,19 @@ func resourceStaticRoute() *schema.Resource {
 	return &schema.Resource{
 		Description: "`unifi_static_route` manages a static route.",
 
-		Create: resourceStaticRouteCreate,
-		Read:   resourceStaticRouteRead,
-		Update: resourceStaticRouteUpdate,
-		Delete: resourceStaticRouteDelete,
+		CreateContext: resourceStaticRouteCreate,
+		ReadContext:   resourceStaticRouteRead,
+		UpdateContext: resourceStaticRouteUpdate,
+		DeleteContext: resourceStaticRouteDelete,
+		CustomizeDiff: resourceStaticRouteCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			State: importSiteAndID,
+			StateContext: importSiteAndName("static route", resourceStaticRouteImportNames),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -41,7 +49,7 @@ func resourceStaticRoute() *schema.Resource {
 			},
 
 			"network": {
-				Description:      "The network subnet address.",
+				Description:      "The network subnet address. This can be an IPv4 or IPv6 CIDR.",
 				Type:             schema.TypeString,
 				Required:         true,
 				ValidateFunc:     cidrValidate,
@@ -60,10 +68,12 @@ func resourceStaticRoute() *schema.Resource {
 			},
 
 			"next_hop": {
-				Description:  "The next hop of the static route (only valid for `nexthop-route` type).",
-				Type:         schema.TypeString,
-				Optional:     true,
-				ValidateFunc: validation.IsIPv4Address,
+				Description: "The next hop of the static route (only valid for `nexthop-route` type). This must be an " +
+					"address of the same IP version as `network`.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     validation.IsIPAddress,
+				DiffSuppressFunc: ipDiffSuppress,
 			},
 			"interface": {
 				Description: "The interface of the static route (only valid for `interface-route` type). This can be `WAN1`, `WAN2`, or a network ID.",
@@ -74,12 +84,30 @@ func resourceStaticRoute() *schema.Resource {
 	}
 }
 
-func resourceStaticRouteCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceStaticRouteCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("network") || !d.NewValueKnown("next_hop") {
+		return nil
+	}
+
+	nextHop := d.Get("next_hop").(string)
+	if nextHop == "" {
+		return nil
+	}
+
+	network := d.Get("network").(string)
+	if ipFamily(network) != ipFamily(nextHop) {
+		return fmt.Errorf("next_hop %q must be the same IP version as network %q", nextHop, network)
+	}
+
+	return nil
+}
+
+func resourceStaticRouteCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourceStaticRouteGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	site := d.Get("site").(string)
@@ -87,14 +115,14 @@ func resourceStaticRouteCreate(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	resp, err := c.c.CreateRouting(context.TODO(), site, req)
+	resp, err := c.c.CreateRouting(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(resp.ID)
 
-	return resourceStaticRouteSetResourceData(resp, d, site)
+	return diag.FromErr(resourceStaticRouteSetResourceData(resp, d, site))
 }
 
 func resourceStaticRouteGetResourceData(d *schema.ResourceData) (*unifi.Routing, error) {
@@ -114,7 +142,7 @@ func resourceStaticRouteGetResourceData(d *schema.ResourceData) (*unifi.Routing,
 	case "interface-route":
 		r.StaticRouteInterface = d.Get("interface").(string)
 	case "nexthop-route":
-		r.StaticRouteNexthop = d.Get("next_hop").(string)
+		r.StaticRouteNexthop = ipNormalize(d.Get("next_hop").(string))
 	case "blackhole":
 	default:
 		return nil, fmt.Errorf("unexpected route type: %q", t)
@@ -149,7 +177,7 @@ func resourceStaticRouteSetResourceData(resp *unifi.Routing, d *schema.ResourceD
 	return nil
 }
 
-func resourceStaticRouteRead(d *schema.ResourceData, meta interface{}) error {
+func resourceStaticRouteRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -159,24 +187,24 @@ func resourceStaticRouteRead(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	resp, err := c.c.GetRouting(context.TODO(), site, id)
+	resp, err := c.c.GetRouting(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		d.SetId("")
 		return nil
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceStaticRouteSetResourceData(resp, d, site)
+	return diag.FromErr(resourceStaticRouteSetResourceData(resp, d, site))
 }
 
-func resourceStaticRouteUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceStaticRouteUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourceStaticRouteGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	req.ID = d.Id()
@@ -187,15 +215,15 @@ func resourceStaticRouteUpdate(d *schema.ResourceData, meta interface{}) error {
 	}
 	req.SiteID = site
 
-	resp, err := c.c.UpdateRouting(context.TODO(), site, req)
+	resp, err := c.c.UpdateRouting(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceStaticRouteSetResourceData(resp, d, site)
+	return diag.FromErr(resourceStaticRouteSetResourceData(resp, d, site))
 }
 
-func resourceStaticRouteDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceStaticRouteDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -204,9 +232,22 @@ func resourceStaticRouteDelete(d *schema.ResourceData, meta interface{}) error {
 	if site == "" {
 		site = c.site
 	}
-	err := c.c.DeleteRouting(context.TODO(), site, id)
+	err := c.c.DeleteRouting(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		return nil
 	}
-	return err
+	return diag.FromErr(err)
+}
+
+func resourceStaticRouteImportNames(ctx context.Context, c unifiClient, site string) ([]importName, error) {
+	routes, err := c.ListRouting(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]importName, 0, len(routes))
+	for _, o := range routes {
+		names = append(names, importName{id: o.ID, name: o.Name})
+	}
+	return names, nil
 }
@@ -1,9 +1,11 @@
 package provider
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccStaticRoute_nextHop(t *testing.T) {
@@ -85,3 +87,80 @@ type      = "interface
 	interface = "WAN2"
 }
 `
+
+func TestStaticRoute_fake(t *testing.T) {
+	c, _ := newFakeClient(t)
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_static_route",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"type":     "nexthop-route",
+					"network":  "172.17.0.0/16",
+					"name":     "tf-acc basic nexthop",
+					"distance": 1,
+					"next_hop": "172.16.0.1",
+				},
+				importStateVerify: true,
+			},
+			{
+				config: map[string]interface{}{
+					"type":      "interface-route",
+					"network":   "172.17.0.0/16",
+					"name":      "tf-acc basic interface",
+					"distance":  1,
+					"interface": "WAN2",
+				},
+				check:             fakeCheckAttr("next_hop", ""),
+				importStateVerify: true,
+				importStateID:     "default:name=tf-acc basic interface",
+			},
+		},
+	})
+}
+
+func TestStaticRoute_fake_ipv6(t *testing.T) {
+	c, _ := newFakeClient(t)
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_static_route",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"type":     "nexthop-route",
+					"network":  "2001:DB8:1::/48",
+					"name":     "tf-acc ipv6 nexthop",
+					"distance": 1,
+					"next_hop": "2001:DB8:0::1",
+				},
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					fakeCheckAttr("network", "2001:db8:1::/48")(t, state)
+					fakeCheckAttr("next_hop", "2001:db8::1")(t, state)
+				},
+				importStateVerify: true,
+			},
+			{
+				config: map[string]interface{}{
+					"type":     "blackhole",
+					"network":  "2001:db8:2::/48",
+					"name":     "tf-acc ipv6 blackhole",
+					"distance": 1,
+				},
+				check:             fakeCheckAttr("network", "2001:db8:2::/48"),
+				importStateVerify: true,
+			},
+		},
+	})
+
+	err := testFakePlanError(t, c, "unifi_static_route", map[string]interface{}{
+		"type":     "nexthop-route",
+		"network":  "2001:db8:1::/48",
+		"name":     "tf-acc mixed",
+		"distance": 1,
+		"next_hop": "172.16.0.1",
+	})
+	if err == nil || !strings.Contains(err.Error(), "must be the same IP version") {
+		t.Fatalf("expected an IP version mismatch, got %v", err)
+	}
+}
@@ -3,7 +3,10 @@
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/paultyng/go-unifi/unifi"
@@ -16,12 +19,19 @@ func resourceUser() *schema.Resource {
 			"Users are created in the controller when observed on the network, so the resource defaults to allowing " +
 			"itself to just take over management of a MAC address, but this can be turned off.",
 
-		Create: resourceUserCreate,
-		Read:   resourceUserRead,
-		Update: resourceUserUpdate,
-		Delete: resourceUserDelete,
+		CreateContext: resourceUserCreate,
+		ReadContext:   resourceUserRead,
+		UpdateContext: resourceUserUpdate,
+		DeleteContext: resourceUserDelete,
+		CustomizeDiff: resourceUserCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			State: importSiteAndID,
+			StateContext: importSiteAndID,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -62,7 +72,8 @@ func resourceUser() *schema.Resource {
 			},
 			// TODO: combine this with output IP for a single attribute ip_address?
 			"fixed_ip": {
-				Description:  "A fixed IPv4 address for this user.",
+				Description: "A fixed IPv4 address for this user. When `network_id` is known, this must be inside the " +
+					"subnet of the network and outside of its DHCP range.",
 				Type:         schema.TypeString,
 				Optional:     true,
 				ValidateFunc: validation.IsIPv4Address,
@@ -107,12 +118,72 @@ func resourceUser() *schema.Resource {
 	}
 }
 
-func resourceUserCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceUserCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	c, ok := meta.(*client)
+	if !ok {
+		return nil
+	}
+
+	if !d.HasChange("fixed_ip") && !d.HasChange("network_id") {
+		return nil
+	}
+	if !d.NewValueKnown("fixed_ip") || !d.NewValueKnown("network_id") {
+		return nil
+	}
+
+	fixedIP := d.Get("fixed_ip").(string)
+	networkID := d.Get("network_id").(string)
+	if fixedIP == "" || networkID == "" {
+		return nil
+	}
+
+	site := d.Get("site").(string)
+	if site == "" {
+		site = c.site
+	}
+
+	network, err := c.c.GetNetwork(ctx, site, networkID)
+	if _, ok := err.(*unifi.NotFoundError); ok {
+		// the network may be created or replaced in the same apply
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return validateFixedIP(fixedIP, network)
+}
+
+// validateFixedIP checks the fixed IP is a usable address of the network that
+// the DHCP server will not hand out.
+func validateFixedIP(fixedIP string, network *unifi.Network) error {
+	ip := net.ParseIP(fixedIP)
+	gateway, subnet, err := net.ParseCIDR(network.IPSubnet)
+	if ip == nil || err != nil {
+		return nil
+	}
+
+	if !subnet.Contains(ip) {
+		return fmt.Errorf("fixed_ip %s is not inside subnet %s of network %q", fixedIP, subnet, network.Name)
+	}
+	if ip.Equal(gateway) {
+		return fmt.Errorf("fixed_ip %s is the gateway address of network %q", fixedIP, network.Name)
+	}
+
+	start, stop := net.ParseIP(network.DHCPDStart), net.ParseIP(network.DHCPDStop)
+	if network.DHCPDEnabled && start != nil && stop != nil && ipCompare(start, ip) <= 0 && ipCompare(ip, stop) <= 0 {
+		return fmt.Errorf("fixed_ip %s is inside the DHCP range %s-%s of network %q", fixedIP, start, stop, network.Name)
+	}
+
+	return nil
+}
+
+func resourceUserCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourceUserGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	allowExisting := d.Get("allow_existing").(bool)
@@ -122,39 +193,39 @@ func resourceUserCreate(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	resp, err := c.c.CreateUser(context.TODO(), site, req)
+	resp, err := c.c.CreateUser(ctx, site, req)
 	if err != nil {
 		var apiErr *unifi.APIError
 		if !errors.As(err, &apiErr) || (apiErr.Message != "api.err.MacUsed" || !allowExisting) {
-			return err
+			return diag.FromErr(err)
 		}
 
 		// mac in use, just absorb it
 		mac := d.Get("mac").(string)
-		existing, err := c.c.GetUserByMAC(context.TODO(), site, mac)
+		existing, err := c.c.GetUserByMAC(ctx, site, mac)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 
 		req.ID = existing.ID
 		req.SiteID = existing.SiteID
 
-		resp, err = c.c.UpdateUser(context.TODO(), site, req)
+		resp, err = c.c.UpdateUser(ctx, site, req)
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
 	d.SetId(resp.ID)
 
 	if d.Get("blocked").(bool) {
-		err := c.c.BlockUserByMAC(context.TODO(), site, d.Get("mac").(string))
+		err := c.c.BlockUserByMAC(ctx, site, d.Get("mac").(string))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 	}
 
-	return resourceUserSetResourceData(resp, d, site)
+	return diag.FromErr(resourceUserSetResourceData(resp, d, site))
 }
 
 func resourceUserGetResourceData(d *schema.ResourceData) (*unifi.User, error) {
@@ -194,7 +265,7 @@ func resourceUserSetResourceData(resp *unifi.User, d *schema.ResourceData, site
 	return nil
 }
 
-func resourceUserRead(d *schema.ResourceData, meta interface{}) error {
+func resourceUserRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -204,31 +275,31 @@ func resourceUserRead(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	resp, err := c.c.GetUser(context.TODO(), site, id)
+	resp, err := c.c.GetUser(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		d.SetId("")
 		return nil
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	// for some reason the IP address is only on this endpoint, so issue another request
-	macResp, err := c.c.GetUserByMAC(context.TODO(), site, resp.MAC)
+	macResp, err := c.c.GetUserByMAC(ctx, site, resp.MAC)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		d.SetId("")
 		return nil
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	resp.IP = macResp.IP
 
-	return resourceUserSetResourceData(resp, d, site)
+	return diag.FromErr(resourceUserSetResourceData(resp, d, site))
 }
 
-func resourceUserUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	site := d.Get("site").(string)
@@ -239,35 +310,35 @@ func resourceUserUpdate(d *schema.ResourceData, meta interface{}) error {
 	if d.HasChange("blocked") {
 		mac := d.Get("mac").(string)
 		if d.Get("blocked").(bool) {
-			err := c.c.BlockUserByMAC(context.TODO(), site, mac)
+			err := c.c.BlockUserByMAC(ctx, site, mac)
 			if err != nil {
-				return err
+				return diag.FromErr(err)
 			}
 		} else {
-			err := c.c.UnblockUserByMAC(context.TODO(), site, mac)
+			err := c.c.UnblockUserByMAC(ctx, site, mac)
 			if err != nil {
-				return err
+				return diag.FromErr(err)
 			}
 		}
 	}
 
 	req, err := resourceUserGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	req.ID = d.Id()
 	req.SiteID = site
 
-	resp, err := c.c.UpdateUser(context.TODO(), site, req)
+	resp, err := c.c.UpdateUser(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceUserSetResourceData(resp, d, site)
+	return diag.FromErr(resourceUserSetResourceData(resp, d, site))
 }
 
-func resourceUserDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceUserDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -282,14 +353,14 @@ func resourceUserDelete(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	// lookup MAC instead of trusting state
-	u, err := c.c.GetUser(context.TODO(), site, id)
+	u, err := c.c.GetUser(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		return nil
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	err = c.c.DeleteUserByMAC(context.TODO(), site, u.MAC)
-	return err
+	err = c.c.DeleteUserByMAC(ctx, site, u.MAC)
+	return diag.FromErr(err)
 }
@@ -3,6 +3,7 @@
 import (
 	"context"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/paultyng/go-unifi/unifi"
 )
@@ -12,12 +13,18 @@ func resourceUserGroup() *schema.Resource {
 		Description: "`unifi_user_group` manages a user group (called \"client group\" in the UI), which can be used " +
 			"to limit bandwidth for groups of users.",
 
-		Create: resourceUserGroupCreate,
-		Read:   resourceUserGroupRead,
-		Update: resourceUserGroupUpdate,
-		Delete: resourceUserGroupDelete,
+		CreateContext: resourceUserGroupCreate,
+		ReadContext:   resourceUserGroupRead,
+		UpdateContext: resourceUserGroupUpdate,
+		DeleteContext: resourceUserGroupDelete,
 		Importer: &schema.ResourceImporter{
-			State: importSiteAndID,
+			StateContext: importSiteAndName("user group", resourceUserGroupImportNames),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -56,12 +63,12 @@ func resourceUserGroup() *schema.Resource {
 	}
 }
 
-func resourceUserGroupCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceUserGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourceUserGroupGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	site := d.Get("site").(string)
@@ -69,14 +76,14 @@ func resourceUserGroupCreate(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	resp, err := c.c.CreateUserGroup(context.TODO(), site, req)
+	resp, err := c.c.CreateUserGroup(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(resp.ID)
 
-	return resourceUserGroupSetResourceData(resp, d)
+	return diag.FromErr(resourceUserGroupSetResourceData(resp, d))
 }
 
 func resourceUserGroupGetResourceData(d *schema.ResourceData) (*unifi.UserGroup, error) {
@@ -95,7 +102,7 @@ func resourceUserGroupSetResourceData(resp *unifi.UserGroup, d *schema.ResourceD
 	return nil
 }
 
-func resourceUserGroupRead(d *schema.ResourceData, meta interface{}) error {
+func resourceUserGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -105,24 +112,24 @@ func resourceUserGroupRead(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	resp, err := c.c.GetUserGroup(context.TODO(), site, id)
+	resp, err := c.c.GetUserGroup(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		d.SetId("")
 		return nil
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceUserGroupSetResourceData(resp, d)
+	return diag.FromErr(resourceUserGroupSetResourceData(resp, d))
 }
 
-func resourceUserGroupUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceUserGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	req, err := resourceUserGroupGetResourceData(d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	req.ID = d.Id()
@@ -133,15 +140,15 @@ func resourceUserGroupUpdate(d *schema.ResourceData, meta interface{}) error {
 	}
 	req.SiteID = site
 
-	resp, err := c.c.UpdateUserGroup(context.TODO(), site, req)
+	resp, err := c.c.UpdateUserGroup(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceUserGroupSetResourceData(resp, d)
+	return diag.FromErr(resourceUserGroupSetResourceData(resp, d))
 }
 
-func resourceUserGroupDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceUserGroupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -150,9 +157,22 @@ func resourceUserGroupDelete(d *schema.ResourceData, meta interface{}) error {
 	if site == "" {
 		site = c.site
 	}
-	err := c.c.DeleteUserGroup(context.TODO(), site, id)
+	err := c.c.DeleteUserGroup(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		return nil
 	}
-	return err
+	return diag.FromErr(err)
+}
+
+func resourceUserGroupImportNames(ctx context.Context, c unifiClient, site string) ([]importName, error) {
+	groups, err := c.ListUserGroup(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]importName, 0, len(groups))
+	for _, o := range groups {
+		names = append(names, importName{id: o.ID, name: o.Name})
+	}
+	return names, nil
 }
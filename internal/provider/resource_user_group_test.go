@@ -1,9 +1,11 @@
 package provider
 
 import (
+	"context"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/paultyng/go-unifi/unifi"
 )
 
 func TestAccUserGroup_basic(t *testing.T) {
@@ -44,3 +46,52 @@ func TestAccUserGroup_basic(t *testing.T) {
 	qos_rate_max_down = 50
 }
 `
+
+func TestUserGroup_fake(t *testing.T) {
+	c, _ := newFakeClient(t)
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_user_group",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"name": "tfacc",
+				},
+				check:             fakeCheckAttr("qos_rate_max_up", "-1"),
+				importStateVerify: true,
+			},
+			{
+				config: map[string]interface{}{
+					"name":              "tfacc",
+					"qos_rate_max_up":   2000,
+					"qos_rate_max_down": 50,
+				},
+				check:             fakeCheckAttr("qos_rate_max_down", "50"),
+				importStateVerify: true,
+				importStateID:     "name=tfacc",
+			},
+		},
+	})
+}
+
+func TestUserGroup_fake_canceled(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	id, err := s.AddObject("default", "usergroup", unifi.UserGroup{Name: "tfacc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := resourceUserGroup()
+	d := r.TestResourceData()
+	d.SetId(id)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if diags := r.ReadContext(ctx, d, c); !diags.HasError() {
+		t.Fatal("expected the read to fail with a canceled context")
+	}
+	if d.Id() != id {
+		t.Fatalf("expected the ID to be kept, got %q", d.Id())
+	}
+}
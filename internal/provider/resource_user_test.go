@@ -4,6 +4,7 @@
 	"context"
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -253,3 +254,146 @@ func testAccUserConfig_existing(mac, name, note string, allow, skip bool) string
 }
 `, mac, name, note, allow, skip)
 }
+
+func TestUser_fake(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_user",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"mac":  "00:00:5e:00:53:10",
+					"name": "tfacc",
+					"note": "tfacc note",
+				},
+				importStateVerify:       true,
+				importStateVerifyIgnore: []string{"allow_existing", "skip_forget_on_destroy"},
+			},
+			{
+				config: map[string]interface{}{
+					"mac":     "00:00:5e:00:53:10",
+					"name":    "tfacc",
+					"note":    "tfacc block true",
+					"blocked": true,
+				},
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					if blocked := s.Object("default", "user", state.ID)["blocked"]; blocked != true {
+						t.Fatalf("expected user to be blocked, got %v", blocked)
+					}
+				},
+			},
+		},
+	})
+}
+
+func TestUser_fake_existing(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	id, err := s.AddObject("default", "user", map[string]interface{}{
+		"mac":  "00:00:5e:00:53:11",
+		"name": "observed",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_user",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"mac":  "00:00:5e:00:53:11",
+					"name": "tfacc",
+				},
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					if state.ID != id {
+						t.Fatalf("expected existing user %q to be managed, got %q", id, state.ID)
+					}
+				},
+			},
+		},
+	})
+}
+
+func TestValidateFixedIP(t *testing.T) {
+	network := &unifi.Network{
+		Name:         "LAN",
+		IPSubnet:     "10.0.0.1/24",
+		DHCPDEnabled: true,
+		DHCPDStart:   "10.0.0.100",
+		DHCPDStop:    "10.0.0.199",
+	}
+
+	for _, c := range []struct {
+		fixedIP       string
+		expectedError string
+	}{
+		{"10.0.0.10", ""},
+		{"10.0.0.200", ""},
+		{"10.0.1.10", `fixed_ip 10.0.1.10 is not inside subnet 10.0.0.0/24 of network "LAN"`},
+		{"10.0.0.1", `fixed_ip 10.0.0.1 is the gateway address of network "LAN"`},
+		{"10.0.0.100", `fixed_ip 10.0.0.100 is inside the DHCP range 10.0.0.100-10.0.0.199 of network "LAN"`},
+		{"10.0.0.150", `fixed_ip 10.0.0.150 is inside the DHCP range 10.0.0.100-10.0.0.199 of network "LAN"`},
+	} {
+		t.Run(c.fixedIP, func(t *testing.T) {
+			err := validateFixedIP(c.fixedIP, network)
+			if c.expectedError == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %q", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != c.expectedError {
+				t.Fatalf("expected error %q, got %v", c.expectedError, err)
+			}
+		})
+	}
+
+	disabled := *network
+	disabled.DHCPDEnabled = false
+	if err := validateFixedIP("10.0.0.150", &disabled); err != nil {
+		t.Fatalf("expected no error with DHCP disabled, got %q", err)
+	}
+}
+
+func TestUser_fake_fixedIP(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	networkID, err := s.AddObject("default", "networkconf", map[string]interface{}{
+		"name":          "LAN",
+		"purpose":       "corporate",
+		"ip_subnet":     "10.0.0.1/24",
+		"dhcpd_enabled": true,
+		"dhcpd_start":   "10.0.0.100",
+		"dhcpd_stop":    "10.0.0.199",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_user",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"mac":        "00:00:5e:00:53:12",
+					"name":       "tfacc",
+					"fixed_ip":   "10.0.0.10",
+					"network_id": networkID,
+				},
+				check: fakeCheckAttr("fixed_ip", "10.0.0.10"),
+			},
+		},
+	})
+
+	err = testFakePlanError(t, c, "unifi_user", map[string]interface{}{
+		"mac":        "00:00:5e:00:53:13",
+		"name":       "tfacc",
+		"fixed_ip":   "10.0.0.150",
+		"network_id": networkID,
+	})
+	if !strings.Contains(err.Error(), "is inside the DHCP range") {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
@@ -6,6 +6,8 @@
 	"log"
 	"strings"
 
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/paultyng/go-unifi/unifi"
@@ -15,12 +17,19 @@ func resourceWLAN() *schema.Resource {
 	return &schema.Resource{
 		Description: "`unifi_wlan` manages a WiFi network / SSID.",
 
-		Create: resourceWLANCreate,
-		Read:   resourceWLANRead,
-		Update: resourceWLANUpdate,
-		Delete: resourceWLANDelete,
+		CreateContext: resourceWLANCreate,
+		ReadContext:   resourceWLANRead,
+		UpdateContext: resourceWLANUpdate,
+		DeleteContext: resourceWLANDelete,
+		CustomizeDiff: resourceWLANCustomizeDiff,
 		Importer: &schema.ResourceImporter{
-			State: importSiteAndID,
+			StateContext: importSiteAndName("WLAN", resourceWLANImportNames),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Read:   schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -47,7 +56,10 @@ func resourceWLAN() *schema.Resource {
 				Required:    true,
 			},
 			"security": {
-				Description:  "The type of WiFi security for this network. Valid values are: `wpapsk`, `wpaeap`, and `open`.",
+				Description: "The type of WiFi security for this network. Valid values are: `wpapsk`, `wpaeap`, and `open`. " +
+					"WPA3 is not a separate value, set `wpa3_support` with `wpapsk` for WPA3 Personal or with `wpaeap` " +
+					"for WPA3 Enterprise. Add `wpa3_transition` to `wpapsk` with `wpa3_support` for WPA2/WPA3 " +
+					"Personal, so WPA2 clients can still connect.",
 				Type:         schema.TypeString,
 				Required:     true,
 				ValidateFunc: validation.StringInSlice([]string{"wpapsk", "wpaeap", "open"}, false),
@@ -137,6 +149,58 @@ func resourceWLAN() *schema.Resource {
 				Optional:    true,
 				Default:     true,
 			},
+			"pmf_mode": {
+				Description: "Protected Management Frames (802.11w) mode. Valid values are `disabled`, `optional` and " +
+					"`required`. WPA3 requires `required`, WPA2/WPA3 transition mode requires `optional`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "disabled",
+				ValidateFunc: validation.StringInSlice([]string{"disabled", "optional", "required"}, false),
+			},
+			"fast_roaming_enabled": {
+				Description: "Indicates whether or not 802.11r fast roaming is enabled, this is not valid if `security` is `open`.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+			},
+			"bss_transition": {
+				Description: "Indicates whether or not BSS transition (802.11v) is enabled, letting APs steer clients " +
+					"to a better AP.",
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"group_rekey": {
+				Description:  "Interval in seconds for renewing the group key, between `60` and `86400`.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3600,
+				ValidateFunc: validation.IntBetween(60, 86400),
+			},
+			"l2_isolation": {
+				Description: "Indicates whether or not clients of this network are isolated from each other on the " +
+					"access points.",
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"dtim_mode": {
+				Description:  "DTIM period mode. Valid values are `default` and `custom`, set `dtim_na` and `dtim_ng` for `custom`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "default",
+				ValidateFunc: validation.StringInSlice([]string{"default", "custom"}, false),
+			},
+			"dtim_na": {
+				Description:  "DTIM period for the 5 GHz band (only valid if `dtim_mode` is `custom`).",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(1, 255),
+			},
+			"dtim_ng": {
+				Description:  "DTIM period for the 2.4 GHz band (only valid if `dtim_mode` is `custom`).",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(1, 255),
+			},
 
 			// controller v6 fields
 			// TODO: this could be defaulted to "both" once v5 controller support is dropped
@@ -161,6 +225,48 @@ func resourceWLAN() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"wpa3_support": {
+				Description: "Indicates whether or not WPA3 is enabled, this requires `security` to be `wpapsk` or " +
+					"`wpaeap`.",
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"wpa3_transition": {
+				Description: "Indicates whether or not WPA2/WPA3 transition mode is enabled, so WPA2 clients can still " +
+					"connect. This requires `wpa3_support` and `security` to be `wpapsk`.",
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"minimum_rssi": {
+				Description:  "Minimum RSSI in dBm for clients to stay connected, between `-90` and `-45`. Clients with a weaker signal are disconnected.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(-90, -45),
+			},
+
+			// controller v7.3 fields
+			"private_preshared_keys": {
+				Description: "Private pre-shared keys (PPSK) for the network. Clients connecting with one of these " +
+					"passphrases are placed on its network instead of `network_id`. Only valid if `security` is `wpapsk`.",
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"passphrase": {
+							Description:  "The passphrase of the key.",
+							Type:         schema.TypeString,
+							Required:     true,
+							Sensitive:    true,
+							ValidateFunc: validation.StringLenBetween(8, 63),
+						},
+						"network_id": {
+							Description: "ID of the network for clients using this key.",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+					},
+				},
+			},
 
 			// controller v5 fields
 			"vlan_id": {
@@ -181,7 +287,7 @@ func resourceWLAN() *schema.Resource {
 	}
 }
 
-func resourceWLANGetResourceData(d *schema.ResourceData, meta interface{}) (*unifi.WLAN, error) {
+func resourceWLANGetResourceData(ctx context.Context, d *schema.ResourceData, meta interface{}) (*unifi.WLAN, error) {
 	c := meta.(*client)
 
 	security := d.Get("security").(string)
@@ -200,35 +306,17 @@ func resourceWLANGetResourceData(d *schema.ResourceData, meta interface{}) (*uni
 		macFilterList = nil
 	}
 
-	// version specific fields and validation
-	networkID := d.Get("network_id").(string)
-	vlan := d.Get("vlan_id").(int)
-	apGroupIDs, err := setToStringSlice(d.Get("ap_group_ids").(*schema.Set))
+	v, err := c.ControllerVersion(ctx)
 	if err != nil {
 		return nil, err
 	}
-	wlanGroupID := d.Get("wlan_group_id").(string)
-	wlanBand := d.Get("wlan_band").(string)
-	switch v := c.ControllerVersion(); {
-	case v.GreaterThanOrEqual(controllerV6):
-		if wlanGroupID != "" {
-			return nil, fmt.Errorf("wlan_group_id is not supported on controller version %q", v)
-		}
-		if vlan != 0 {
-			return nil, fmt.Errorf("vlan_id %d is not supported on controller version %q", vlan, v)
-		}
-	case v.GreaterThanOrEqual(controllerV5):
-		if networkID != "" {
-			return nil, fmt.Errorf("network_id is not supported on controller version %q", v)
-		}
-		if len(apGroupIDs) > 0 {
-			return nil, fmt.Errorf("ap_group_ids is not supported on controller version %q", v)
-		}
-		if wlanBand != "" {
-			return nil, fmt.Errorf("wlan_band is not supported on controller version %q", v)
-		}
-	default:
-		return nil, fmt.Errorf("controller version %q not supported", v)
+	// values that were unknown while planning can only be checked here
+	err = checkCapabilities("unifi_wlan", v, func(attr string) bool {
+		_, ok := d.GetOk(attr)
+		return ok
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	schedule, err := listToScheduleStrings(d.Get("schedule").([]interface{}))
@@ -237,13 +325,11 @@ func resourceWLANGetResourceData(d *schema.ResourceData, meta interface{}) (*uni
 	}
 	log.Printf("[TRACE] TF Schedule: %#v", schedule)
 
-	return &unifi.WLAN{
+	req := &unifi.WLAN{
 		Name:                    d.Get("name").(string),
 		XPassphrase:             passphrase,
 		HideSSID:                d.Get("hide_ssid").(bool),
 		IsGuest:                 d.Get("is_guest").(bool),
-		NetworkID:               networkID,
-		ApGroupIDs:              apGroupIDs,
 		UserGroupID:             d.Get("user_group_id").(string),
 		Security:                security,
 		MulticastEnhanceEnabled: d.Get("multicast_enhance").(bool),
@@ -253,12 +339,6 @@ func resourceWLANGetResourceData(d *schema.ResourceData, meta interface{}) (*uni
 		RADIUSProfileID:         d.Get("radius_profile_id").(string),
 		Schedule:                schedule,
 		ScheduleEnabled:         len(schedule) > 0,
-		WLANBand:                wlanBand,
-
-		// v5
-		VLAN:        vlan,
-		WLANGroupID: wlanGroupID,
-		VLANEnabled: vlan != 0 && vlan != 1,
 
 		// TODO: add to schema
 		WPAEnc:             "ccmp",
@@ -266,19 +346,143 @@ func resourceWLANGetResourceData(d *schema.ResourceData, meta interface{}) (*uni
 		Enabled:            true,
 		NameCombineEnabled: true,
 
-		GroupRekey:               3600,
-		DTIMMode:                 "default",
+		PMFMode:            d.Get("pmf_mode").(string),
+		FastRoamingEnabled: d.Get("fast_roaming_enabled").(bool),
+		BssTransition:      d.Get("bss_transition").(bool),
+		GroupRekey:         d.Get("group_rekey").(int),
+		L2Isolation:        d.Get("l2_isolation").(bool),
+		DTIMMode:           d.Get("dtim_mode").(string),
+
 		No2GhzOui:                d.Get("no2ghz_oui").(bool),
 		MinrateNgCckRatesEnabled: true,
-	}, nil
+	}
+
+	if req.DTIMMode == "custom" {
+		req.DTIMNa = d.Get("dtim_na").(int)
+		req.DTIMNg = d.Get("dtim_ng").(int)
+	}
+
+	// only send the fields the controller version knows about
+	if supportsAttribute("unifi_wlan", "network_id", v) {
+		req.NetworkID = d.Get("network_id").(string)
+	}
+	if supportsAttribute("unifi_wlan", "ap_group_ids", v) {
+		req.ApGroupIDs, err = setToStringSlice(d.Get("ap_group_ids").(*schema.Set))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if supportsAttribute("unifi_wlan", "wlan_band", v) {
+		req.WLANBand = d.Get("wlan_band").(string)
+	}
+	if supportsAttribute("unifi_wlan", "vlan_id", v) {
+		vlan := d.Get("vlan_id").(int)
+		req.VLAN = vlan
+		req.VLANEnabled = vlan != 0 && vlan != 1
+	}
+	if supportsAttribute("unifi_wlan", "wlan_group_id", v) {
+		req.WLANGroupID = d.Get("wlan_group_id").(string)
+	}
+
+	return req, nil
+}
+
+// wlanExtraAttributes are the attributes written with UpdateWLANExtra instead
+// of the SDK request.
+var wlanExtraAttributes = []string{
+	"private_preshared_keys",
+	"wpa3_support",
+	"wpa3_transition",
+	"minimum_rssi",
 }
 
-func resourceWLANCreate(d *schema.ResourceData, meta interface{}) error {
+// wlanExtraSupported reports whether the controller supports any of the
+// settings in wlanExtra.
+func wlanExtraSupported(v *version.Version) bool {
+	for _, attr := range wlanExtraAttributes {
+		if supportsAttribute("unifi_wlan", attr, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceWLANGetExtra returns the settings that are written separately from
+// the SDK request, leaving out the ones the controller version does not know.
+func resourceWLANGetExtra(d *schema.ResourceData, v *version.Version) *wlanExtra {
+	extra := &wlanExtra{}
+
+	if supportsAttribute("unifi_wlan", "wpa3_support", v) {
+		wpa3 := d.Get("wpa3_support").(bool)
+		extra.WPA3Support = &wpa3
+	}
+	if supportsAttribute("unifi_wlan", "wpa3_transition", v) {
+		transition := d.Get("wpa3_transition").(bool)
+		extra.WPA3Transition = &transition
+	}
+	if supportsAttribute("unifi_wlan", "minimum_rssi", v) {
+		rssi := d.Get("minimum_rssi").(int)
+		enabled := rssi != 0
+		extra.MinRSSIEnabled = &enabled
+		if enabled {
+			extra.MinRSSI = &rssi
+		}
+	}
+	if supportsAttribute("unifi_wlan", "private_preshared_keys", v) {
+		keys := []wlanPrivatePresharedKey{}
+		for _, raw := range d.Get("private_preshared_keys").([]interface{}) {
+			k := raw.(map[string]interface{})
+			keys = append(keys, wlanPrivatePresharedKey{
+				Password:  k["passphrase"].(string),
+				NetworkID: k["network_id"].(string),
+			})
+		}
+		enabled := len(keys) > 0
+		extra.PrivatePresharedKeysEnabled = &enabled
+		extra.PrivatePresharedKeys = &keys
+	}
+
+	return extra
+}
+
+// resourceWLANUpdateExtra writes the settings unifi.WLAN does not support, it
+// returns nil if the controller does not support any of them.
+func resourceWLANUpdateExtra(ctx context.Context, d *schema.ResourceData, meta interface{}, site, id string) (*wlanExtra, error) {
 	c := meta.(*client)
 
-	req, err := resourceWLANGetResourceData(d, meta)
+	v, err := c.ControllerVersion(ctx)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if !wlanExtraSupported(v) {
+		return nil, nil
+	}
+
+	return c.c.UpdateWLANExtra(ctx, site, id, resourceWLANGetExtra(d, v))
+}
+
+// resourceWLANGetExtraResponse reads the settings unifi.WLAN does not support,
+// it returns nil if the controller does not support any of them.
+func resourceWLANGetExtraResponse(ctx context.Context, meta interface{}, site, id string) (*wlanExtra, error) {
+	c := meta.(*client)
+
+	v, err := c.ControllerVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !wlanExtraSupported(v) {
+		return nil, nil
+	}
+
+	return c.c.GetWLANExtra(ctx, site, id)
+}
+
+func resourceWLANCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client)
+
+	req, err := resourceWLANGetResourceData(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
 	site := d.Get("site").(string)
@@ -286,18 +490,28 @@ func resourceWLANCreate(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	resp, err := c.c.CreateWLAN(context.TODO(), site, req)
+	resp, err := c.c.CreateWLAN(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	d.SetId(resp.ID)
 
-	return resourceWLANSetResourceData(resp, d, meta, site)
+	extra, err := resourceWLANUpdateExtra(ctx, d, meta, site, resp.ID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.FromErr(resourceWLANSetResourceData(ctx, resp, extra, d, meta, site))
 }
 
-func resourceWLANSetResourceData(resp *unifi.WLAN, d *schema.ResourceData, meta interface{}, site string) error {
-	// c := meta.(*client)
+func resourceWLANSetResourceData(ctx context.Context, resp *unifi.WLAN, extra *wlanExtra, d *schema.ResourceData, meta interface{}, site string) error {
+	c := meta.(*client)
+
+	v, err := c.ControllerVersion(ctx)
+	if err != nil {
+		return err
+	}
 
 	vlan := 0
 	if resp.VLANEnabled {
@@ -321,6 +535,23 @@ func resourceWLANSetResourceData(resp *unifi.WLAN, d *schema.ResourceData, meta
 
 	apGroupIDs := stringSliceToSet(resp.ApGroupIDs)
 
+	// the controller leaves these empty until they are changed from the defaults
+	pmfMode := resp.PMFMode
+	if pmfMode == "" {
+		pmfMode = "disabled"
+	}
+	groupRekey := resp.GroupRekey
+	if groupRekey == 0 {
+		groupRekey = 3600
+	}
+	dtimMode := resp.DTIMMode
+	dtimNa, dtimNg := 0, 0
+	if dtimMode == "custom" {
+		dtimNa, dtimNg = resp.DTIMNa, resp.DTIMNg
+	} else {
+		dtimMode = "default"
+	}
+
 	log.Printf("[TRACE] API Schedule: %#v", resp.Schedule)
 	schedule, err := listFromScheduleStrings(resp.Schedule)
 	if err != nil {
@@ -340,22 +571,64 @@ func resourceWLANSetResourceData(resp *unifi.WLAN, d *schema.ResourceData, meta
 	d.Set("mac_filter_policy", macFilterPolicy)
 	d.Set("radius_profile_id", resp.RADIUSProfileID)
 	d.Set("schedule", schedule)
-	d.Set("wlan_band", resp.WLANBand)
 	d.Set("no2ghz_oui", resp.No2GhzOui)
+	d.Set("pmf_mode", pmfMode)
+	d.Set("fast_roaming_enabled", resp.FastRoamingEnabled)
+	d.Set("bss_transition", resp.BssTransition)
+	d.Set("group_rekey", groupRekey)
+	d.Set("l2_isolation", resp.L2Isolation)
+	d.Set("dtim_mode", dtimMode)
+	d.Set("dtim_na", dtimNa)
+	d.Set("dtim_ng", dtimNg)
+
+	if supportsAttribute("unifi_wlan", "ap_group_ids", v) {
+		d.Set("ap_group_ids", apGroupIDs)
+	}
+	if supportsAttribute("unifi_wlan", "network_id", v) {
+		d.Set("network_id", resp.NetworkID)
+	}
+	if supportsAttribute("unifi_wlan", "wlan_band", v) {
+		d.Set("wlan_band", resp.WLANBand)
+	}
+	if supportsAttribute("unifi_wlan", "vlan_id", v) {
+		d.Set("vlan_id", vlan)
+	}
+	if supportsAttribute("unifi_wlan", "wlan_group_id", v) {
+		d.Set("wlan_group_id", resp.WLANGroupID)
+	}
 
-	// switch v := c.ControllerVersion(); {
-	// case v.GreaterThanOrEqual(controllerV6):
-	d.Set("ap_group_ids", apGroupIDs)
-	d.Set("network_id", resp.NetworkID)
-	// case v.GreaterThanOrEqual(controllerV5):
-	d.Set("vlan_id", vlan)
-	d.Set("wlan_group_id", resp.WLANGroupID)
-	// }
+	if extra != nil {
+		if supportsAttribute("unifi_wlan", "wpa3_support", v) {
+			d.Set("wpa3_support", extra.WPA3Support != nil && *extra.WPA3Support)
+		}
+		if supportsAttribute("unifi_wlan", "wpa3_transition", v) {
+			d.Set("wpa3_transition", extra.WPA3Transition != nil && *extra.WPA3Transition)
+		}
+		if supportsAttribute("unifi_wlan", "minimum_rssi", v) {
+			rssi := 0
+			if extra.MinRSSIEnabled != nil && *extra.MinRSSIEnabled && extra.MinRSSI != nil {
+				rssi = *extra.MinRSSI
+			}
+			d.Set("minimum_rssi", rssi)
+		}
+		if supportsAttribute("unifi_wlan", "private_preshared_keys", v) {
+			keys := []interface{}{}
+			if extra.PrivatePresharedKeysEnabled != nil && *extra.PrivatePresharedKeysEnabled && extra.PrivatePresharedKeys != nil {
+				for _, k := range *extra.PrivatePresharedKeys {
+					keys = append(keys, map[string]interface{}{
+						"passphrase": k.Password,
+						"network_id": k.NetworkID,
+					})
+				}
+			}
+			d.Set("private_preshared_keys", keys)
+		}
+	}
 
 	return nil
 }
 
-func resourceWLANRead(d *schema.ResourceData, meta interface{}) error {
+func resourceWLANRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -364,24 +637,33 @@ func resourceWLANRead(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	resp, err := c.c.GetWLAN(context.TODO(), site, id)
+	resp, err := c.c.GetWLAN(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		d.SetId("")
 		return nil
 	}
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	return resourceWLANSetResourceData(resp, d, meta, site)
+	extra, err := resourceWLANGetExtraResponse(ctx, meta, site, id)
+	if _, ok := err.(*unifi.NotFoundError); ok {
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diag.FromErr(resourceWLANSetResourceData(ctx, resp, extra, d, meta, site))
 }
 
-func resourceWLANUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceWLANUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
-	req, err := resourceWLANGetResourceData(d, meta)
+	req, err := resourceWLANGetResourceData(ctx, d, meta)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	req.ID = d.Id()
@@ -391,15 +673,120 @@ func resourceWLANUpdate(d *schema.ResourceData, meta interface{}) error {
 	}
 	req.SiteID = site
 
-	resp, err := c.c.UpdateWLAN(context.TODO(), site, req)
+	resp, err := c.c.UpdateWLAN(ctx, site, req)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
+	}
+
+	extra, err := resourceWLANUpdateExtra(ctx, d, meta, site, resp.ID)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
-	return resourceWLANSetResourceData(resp, d, meta, site)
+	return diag.FromErr(resourceWLANSetResourceData(ctx, resp, extra, d, meta, site))
 }
 
-func resourceWLANDelete(d *schema.ResourceData, meta interface{}) error {
+// wlanSecurity holds the attributes whose combinations have to be validated
+// together.
+type wlanSecurity struct {
+	security             string
+	wpa3Support          bool
+	wpa3Transition       bool
+	pmfMode              string
+	fastRoaming          bool
+	privatePresharedKeys bool
+}
+
+func (s wlanSecurity) validate() error {
+	if s.wpa3Support && s.security != "wpapsk" && s.security != "wpaeap" {
+		return fmt.Errorf("wpa3_support requires security to be wpapsk or wpaeap, got %q", s.security)
+	}
+	if s.wpa3Transition {
+		if !s.wpa3Support {
+			return fmt.Errorf("wpa3_transition requires wpa3_support")
+		}
+		if s.security != "wpapsk" {
+			return fmt.Errorf("wpa3_transition requires security to be wpapsk, got %q", s.security)
+		}
+		// WPA2 clients may not support PMF
+		if s.pmfMode != "optional" {
+			return fmt.Errorf("wpa3_transition requires pmf_mode to be optional, got %q", s.pmfMode)
+		}
+	} else if s.wpa3Support && s.pmfMode != "required" {
+		return fmt.Errorf("wpa3_support requires pmf_mode to be required, got %q", s.pmfMode)
+	}
+
+	if s.security == "open" {
+		if s.pmfMode != "disabled" {
+			return fmt.Errorf("pmf_mode %q requires security to be wpapsk or wpaeap", s.pmfMode)
+		}
+		if s.fastRoaming {
+			return fmt.Errorf("fast_roaming_enabled requires security to be wpapsk or wpaeap")
+		}
+	}
+
+	if s.privatePresharedKeys {
+		if s.security != "wpapsk" {
+			return fmt.Errorf("private_preshared_keys requires security to be wpapsk, got %q", s.security)
+		}
+		if s.wpa3Support {
+			return fmt.Errorf("private_preshared_keys cannot be used with wpa3_support")
+		}
+	}
+
+	return nil
+}
+
+func resourceWLANCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	keys := diff.Get("private_preshared_keys").([]interface{})
+
+	// the combinations can only be checked once all of the values are known
+	known := true
+	for _, k := range []string{"security", "wpa3_support", "wpa3_transition", "pmf_mode", "fast_roaming_enabled"} {
+		known = known && diff.NewValueKnown(k)
+	}
+	if known {
+		err := wlanSecurity{
+			security:             diff.Get("security").(string),
+			wpa3Support:          diff.Get("wpa3_support").(bool),
+			wpa3Transition:       diff.Get("wpa3_transition").(bool),
+			pmfMode:              diff.Get("pmf_mode").(string),
+			fastRoaming:          diff.Get("fast_roaming_enabled").(bool),
+			privatePresharedKeys: len(keys) > 0,
+		}.validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	if diff.NewValueKnown("dtim_na") && diff.NewValueKnown("dtim_ng") {
+		custom := diff.Get("dtim_mode").(string) == "custom"
+		dtimNa, dtimNg := diff.Get("dtim_na").(int), diff.Get("dtim_ng").(int)
+		if custom && (dtimNa == 0 || dtimNg == 0) {
+			return fmt.Errorf("dtim_mode custom requires dtim_na and dtim_ng")
+		}
+		if !custom && (dtimNa != 0 || dtimNg != 0) {
+			return fmt.Errorf("dtim_na and dtim_ng are only valid if dtim_mode is custom")
+		}
+	}
+
+	// the controller identifies the network of a client by its passphrase
+	seen := map[string]bool{}
+	for i, raw := range keys {
+		if !diff.NewValueKnown(fmt.Sprintf("private_preshared_keys.%d.passphrase", i)) {
+			continue
+		}
+		passphrase := raw.(map[string]interface{})["passphrase"].(string)
+		if seen[passphrase] {
+			return fmt.Errorf("private_preshared_keys.%d.passphrase is used by another key", i)
+		}
+		seen[passphrase] = true
+	}
+
+	return nil
+}
+
+func resourceWLANDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client)
 
 	id := d.Id()
@@ -408,11 +795,11 @@ func resourceWLANDelete(d *schema.ResourceData, meta interface{}) error {
 		site = c.site
 	}
 
-	err := c.c.DeleteWLAN(context.TODO(), site, id)
+	err := c.c.DeleteWLAN(ctx, site, id)
 	if _, ok := err.(*unifi.NotFoundError); ok {
 		return nil
 	}
-	return err
+	return diag.FromErr(err)
 }
 
 func listToScheduleStrings(list []interface{}) ([]string, error) {
@@ -471,3 +858,16 @@ func listFromScheduleStrings(ss []string) ([]interface{}, error) {
 	}
 	return list, nil
 }
+
+func resourceWLANImportNames(ctx context.Context, c unifiClient, site string) ([]importName, error) {
+	wlans, err := c.ListWLAN(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]importName, 0, len(wlans))
+	for _, o := range wlans {
+		names = append(names, importName{id: o.ID, name: o.Name})
+	}
+	return names, nil
+}
@@ -1,9 +1,11 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -258,6 +260,36 @@ func TestAccWLAN_no2ghz_oui(t *testing.T) {
 	})
 }
 
+func TestAccWLAN_wpa3(t *testing.T) {
+	vlanID := getTestVLAN(t)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			preCheck(t)
+			preCheckV6Only(t)
+			wlanPreCheck(t)
+		},
+		ProviderFactories: providerFactories,
+		CheckDestroy: func(*terraform.State) error {
+			// TODO: actual CheckDestroy
+
+			<-wlanConcurrency
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccWLANConfig_wpa3(vlanID, false, "required"),
+				Check:  resource.TestCheckResourceAttr("unifi_wlan.test", "wpa3_support", "true"),
+			},
+			importStep("unifi_wlan.test"),
+			{
+				Config: testAccWLANConfig_wpa3(vlanID, true, "optional"),
+				Check:  resource.TestCheckResourceAttr("unifi_wlan.test", "wpa3_transition", "true"),
+			},
+			importStep("unifi_wlan.test"),
+		},
+	})
+}
+
 func testAccWLANConfig_wpapsk(vlanID int) string {
 	return fmt.Sprintf(`
 data "unifi_ap_group" "default" {
@@ -472,3 +504,382 @@ func testAccWLANConfig_no2ghz_oui(vlanID int) string {
 }
 `, vlanID)
 }
+
+func testAccWLANConfig_wpa3(vlanID int, transition bool, pmfMode string) string {
+	return fmt.Sprintf(`
+data "unifi_ap_group" "default" {
+}
+
+data "unifi_user_group" "default" {
+}
+
+resource "unifi_network" "test" {
+	name    = "tfacc"
+	purpose = "corporate"
+
+	subnet        = cidrsubnet("10.0.0.0/8", 6, %[1]d)
+	vlan_id       = %[1]d
+}
+
+resource "unifi_wlan" "test" {
+	name          = "tfacc-wpa3"
+	network_id    = unifi_network.test.id
+	passphrase    = "12345678"
+	ap_group_ids  = [data.unifi_ap_group.default.id]
+	user_group_id = data.unifi_user_group.default.id
+	security      = "wpapsk"
+
+	wpa3_support    = true
+	wpa3_transition = %[2]t
+	pmf_mode        = %[3]q
+
+	fast_roaming_enabled = true
+	bss_transition       = true
+	group_rekey          = 7200
+	l2_isolation         = true
+	minimum_rssi         = -75
+
+	dtim_mode = "custom"
+	dtim_na   = 3
+	dtim_ng   = 1
+}
+`, vlanID, transition, pmfMode)
+}
+
+func TestWLAN_fake(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	networkID, err := s.AddObject("default", "networkconf", map[string]interface{}{"name": "tfacc", "purpose": "corporate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	userGroupID, err := s.AddObject("default", "usergroup", map[string]interface{}{"name": "Default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_wlan",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"name":              "tfacc-wpapsk",
+					"network_id":        networkID,
+					"passphrase":        "12345678",
+					"user_group_id":     userGroupID,
+					"security":          "wpapsk",
+					"multicast_enhance": true,
+				},
+				importStateVerify:       true,
+				importStateVerifyIgnore: []string{"passphrase"},
+				importStateID:           "name=tfacc-wpapsk",
+			},
+			{
+				config: map[string]interface{}{
+					"name":               "tfacc-open",
+					"network_id":         networkID,
+					"user_group_id":      userGroupID,
+					"security":           "open",
+					"mac_filter_enabled": true,
+					"mac_filter_list":    []interface{}{"ab:cd:ef:12:34:56"},
+					"mac_filter_policy":  "allow",
+				},
+				check: fakeCheckAttr("mac_filter_list.#", "1"),
+			},
+		},
+	})
+}
+
+// TestWLAN_fake_upgrade refreshes a WLAN created before the roaming and
+// security settings were added, the settings left out of the configuration
+// must match what was sent before.
+func TestWLAN_fake_upgrade(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	networkID, err := s.AddObject("default", "networkconf", map[string]interface{}{"name": "tfacc", "purpose": "corporate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	userGroupID, err := s.AddObject("default", "usergroup", map[string]interface{}{"name": "Default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := s.AddObject("default", "wlanconf", map[string]interface{}{
+		"name":                 "tfacc-upgrade",
+		"security":             "wpapsk",
+		"wpa_mode":             "wpa2",
+		"wpa_enc":              "ccmp",
+		"x_passphrase":         "12345678",
+		"networkconf_id":       networkID,
+		"usergroup_id":         userGroupID,
+		"enabled":              true,
+		"no2ghz_oui":           true,
+		"bss_transition":       false,
+		"fast_roaming_enabled": false,
+		"l2_isolation":         false,
+		"pmf_mode":             "disabled",
+		"group_rekey":          3600,
+		"dtim_mode":            "default",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := New("test")().ResourcesMap["unifi_wlan"]
+	state := testFakeRefresh(t, r, &terraform.InstanceState{
+		ID:         id,
+		Attributes: map[string]string{"id": id, "site": "default"},
+	}, c)
+
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":          "tfacc-upgrade",
+		"network_id":    networkID,
+		"passphrase":    "12345678",
+		"user_group_id": userGroupID,
+		"security":      "wpapsk",
+	}), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != nil && !diff.Empty() {
+		t.Fatalf("expected an empty plan after upgrading, got changes to %s", diffAttributes(diff))
+	}
+}
+
+func TestWLAN_fake_privatePresharedKeys(t *testing.T) {
+	c, s := newFakeClient(t)
+	s.SetVersion("7.3.83")
+
+	networkID, err := s.AddObject("default", "networkconf", map[string]interface{}{"name": "tfacc", "purpose": "corporate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	iotID, err := s.AddObject("default", "networkconf", map[string]interface{}{"name": "tfacc-iot", "purpose": "corporate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	userGroupID, err := s.AddObject("default", "usergroup", map[string]interface{}{"name": "Default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := func(keys ...interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"name":                   "tfacc-ppsk",
+			"network_id":             networkID,
+			"passphrase":             "12345678",
+			"user_group_id":          userGroupID,
+			"security":               "wpapsk",
+			"private_preshared_keys": keys,
+		}
+	}
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_wlan",
+		steps: []fakeStep{
+			{
+				config: config(
+					map[string]interface{}{"passphrase": "iot-passphrase", "network_id": iotID},
+					map[string]interface{}{"passphrase": "lan-passphrase", "network_id": networkID},
+				),
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					fakeCheckAttr("private_preshared_keys.#", "2")(t, state)
+					fakeCheckAttr("private_preshared_keys.0.network_id", iotID)(t, state)
+
+					o := s.Objects("default", "wlanconf")[0]
+					if o["private_preshared_keys_enabled"] != true {
+						t.Errorf("expected private_preshared_keys_enabled, got %v", o["private_preshared_keys_enabled"])
+					}
+				},
+				importStateVerify:       true,
+				importStateVerifyIgnore: []string{"passphrase"},
+			},
+			{
+				config: config(),
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					fakeCheckAttr("private_preshared_keys.#", "0")(t, state)
+
+					o := s.Objects("default", "wlanconf")[0]
+					if o["private_preshared_keys_enabled"] != false {
+						t.Errorf("expected private_preshared_keys to be disabled, got %v", o["private_preshared_keys_enabled"])
+					}
+				},
+			},
+		},
+	})
+
+	err = testFakePlanError(t, c, "unifi_wlan", map[string]interface{}{
+		"name":          "tfacc-ppsk",
+		"user_group_id": userGroupID,
+		"security":      "open",
+		"private_preshared_keys": []interface{}{
+			map[string]interface{}{"passphrase": "iot-passphrase", "network_id": iotID},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "requires security to be wpapsk") {
+		t.Fatalf("expected a security error, got %v", err)
+	}
+
+	err = testFakePlanError(t, c, "unifi_wlan", config(
+		map[string]interface{}{"passphrase": "iot-passphrase", "network_id": iotID},
+		map[string]interface{}{"passphrase": "iot-passphrase", "network_id": networkID},
+	))
+	if err == nil || !strings.Contains(err.Error(), "is used by another key") {
+		t.Fatalf("expected a duplicate passphrase error, got %v", err)
+	}
+
+	c, _ = newFakeClient(t)
+	err = testFakePlanError(t, c, "unifi_wlan", config(
+		map[string]interface{}{"passphrase": "iot-passphrase", "network_id": iotID},
+	))
+	if err == nil || !strings.Contains(err.Error(), "private_preshared_keys is not supported") {
+		t.Fatalf("expected private_preshared_keys to be rejected, got %v", err)
+	}
+}
+
+func TestWLANSecurityValidate(t *testing.T) {
+	for i, c := range []struct {
+		expectedErr string
+		s           wlanSecurity
+	}{
+		{"", wlanSecurity{security: "open", pmfMode: "disabled"}},
+		{"", wlanSecurity{security: "wpapsk", pmfMode: "disabled", fastRoaming: true}},
+		{"", wlanSecurity{security: "wpapsk", pmfMode: "optional"}},
+		{"", wlanSecurity{security: "wpapsk", wpa3Support: true, pmfMode: "required"}},
+		{"", wlanSecurity{security: "wpapsk", wpa3Support: true, wpa3Transition: true, pmfMode: "optional"}},
+		{"", wlanSecurity{security: "wpaeap", wpa3Support: true, pmfMode: "required"}},
+		{"", wlanSecurity{security: "wpapsk", pmfMode: "disabled", privatePresharedKeys: true}},
+
+		{"wpa3_support requires security", wlanSecurity{security: "open", wpa3Support: true, pmfMode: "required"}},
+		{"wpa3_support requires pmf_mode to be required", wlanSecurity{security: "wpapsk", wpa3Support: true, pmfMode: "optional"}},
+		{"wpa3_support requires pmf_mode to be required", wlanSecurity{security: "wpaeap", wpa3Support: true, pmfMode: "disabled"}},
+		{"wpa3_transition requires wpa3_support", wlanSecurity{security: "wpapsk", wpa3Transition: true, pmfMode: "optional"}},
+		{"wpa3_transition requires security", wlanSecurity{security: "wpaeap", wpa3Support: true, wpa3Transition: true, pmfMode: "optional"}},
+		{"wpa3_transition requires pmf_mode", wlanSecurity{security: "wpapsk", wpa3Support: true, wpa3Transition: true, pmfMode: "required"}},
+		{"pmf_mode \"optional\" requires security", wlanSecurity{security: "open", pmfMode: "optional"}},
+		{"fast_roaming_enabled requires security", wlanSecurity{security: "open", pmfMode: "disabled", fastRoaming: true}},
+		{"private_preshared_keys requires security", wlanSecurity{security: "wpaeap", pmfMode: "disabled", privatePresharedKeys: true}},
+		{"private_preshared_keys cannot be used with wpa3_support", wlanSecurity{security: "wpapsk", wpa3Support: true, pmfMode: "required", privatePresharedKeys: true}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := c.s.validate()
+			if c.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+				t.Fatalf("expected error containing %q, got %v", c.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestWLAN_fake_security(t *testing.T) {
+	c, s := newFakeClient(t)
+
+	networkID, err := s.AddObject("default", "networkconf", map[string]interface{}{"name": "tfacc", "purpose": "corporate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	userGroupID, err := s.AddObject("default", "usergroup", map[string]interface{}{"name": "Default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testFakeResource(t, c, fakeTestCase{
+		resource: "unifi_wlan",
+		steps: []fakeStep{
+			{
+				config: map[string]interface{}{
+					"name":                 "tfacc-wpa3",
+					"network_id":           networkID,
+					"passphrase":           "12345678",
+					"user_group_id":        userGroupID,
+					"security":             "wpapsk",
+					"wpa3_support":         true,
+					"pmf_mode":             "required",
+					"fast_roaming_enabled": true,
+					"bss_transition":       true,
+					"group_rekey":          7200,
+					"l2_isolation":         true,
+					"minimum_rssi":         -75,
+					"dtim_mode":            "custom",
+					"dtim_na":              3,
+					"dtim_ng":              1,
+				},
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					o := s.Objects("default", "wlanconf")[0]
+					for k, v := range map[string]interface{}{
+						"wpa3_support":         true,
+						"pmf_mode":             "required",
+						"fast_roaming_enabled": true,
+						"bss_transition":       true,
+						"group_rekey":          float64(7200),
+						"l2_isolation":         true,
+						"minrssi_enabled":      true,
+						"minrssi":              float64(-75),
+						"dtim_mode":            "custom",
+						"dtim_na":              float64(3),
+						"dtim_ng":              float64(1),
+					} {
+						if o[k] != v {
+							t.Errorf("expected %s = %v, got %v", k, v, o[k])
+						}
+					}
+				},
+				importStateVerify:       true,
+				importStateVerifyIgnore: []string{"passphrase"},
+			},
+			{
+				config: map[string]interface{}{
+					"name":            "tfacc-wpa3",
+					"network_id":      networkID,
+					"passphrase":      "12345678",
+					"user_group_id":   userGroupID,
+					"security":        "wpapsk",
+					"wpa3_support":    true,
+					"wpa3_transition": true,
+					"pmf_mode":        "optional",
+				},
+				check: func(t *testing.T, state *terraform.InstanceState) {
+					fakeCheckAttr("wpa3_transition", "true")(t, state)
+					fakeCheckAttr("minimum_rssi", "0")(t, state)
+					fakeCheckAttr("dtim_mode", "default")(t, state)
+					fakeCheckAttr("group_rekey", "3600")(t, state)
+					fakeCheckAttr("bss_transition", "false")(t, state)
+
+					o := s.Objects("default", "wlanconf")[0]
+					if o["minrssi_enabled"] != false {
+						t.Errorf("expected minrssi to be disabled, got %v", o["minrssi_enabled"])
+					}
+				},
+				importStateVerify:       true,
+				importStateVerifyIgnore: []string{"passphrase"},
+			},
+		},
+	})
+
+	err = testFakePlanError(t, c, "unifi_wlan", map[string]interface{}{
+		"name":          "tfacc-wpa3",
+		"user_group_id": userGroupID,
+		"passphrase":    "12345678",
+		"security":      "wpapsk",
+		"wpa3_support":  true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "wpa3_support requires pmf_mode to be required") {
+		t.Fatalf("expected a pmf_mode error, got %v", err)
+	}
+
+	err = testFakePlanError(t, c, "unifi_wlan", map[string]interface{}{
+		"name":          "tfacc-dtim",
+		"user_group_id": userGroupID,
+		"security":      "open",
+		"dtim_mode":     "custom",
+		"dtim_na":       3,
+	})
+	if err == nil || !strings.Contains(err.Error(), "dtim_mode custom requires dtim_na and dtim_ng") {
+		t.Fatalf("expected a DTIM error, got %v", err)
+	}
+}
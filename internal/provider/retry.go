@@ -0,0 +1,296 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultMaxRetries   = 3
+	defaultRetryWaitMin = 1 * time.Second
+	defaultRetryWaitMax = 30 * time.Second
+)
+
+// retryTransport retries requests that failed because the controller was
+// temporarily unavailable and transparently logs in again when the session
+// has expired.
+//
+// The login request sent by the SDK is recorded so it can be replayed, this
+// happens at the transport level because the SDK holds its request lock for
+// the duration of a request, so calling Login from here would deadlock.
+type retryTransport struct {
+	next http.RoundTripper
+	jar  http.CookieJar
+
+	maxRetries int
+	waitMin    time.Duration
+	waitMax    time.Duration
+
+	loginMu   sync.Mutex
+	loginURL  string
+	loginBody []byte
+
+	// csrf is the latest CSRF token of the session, see syncCSRF
+	csrfMu sync.Mutex
+	csrf   string
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodPost && isLoginPath(req.URL.Path) {
+		if err := t.recordLogin(req); err != nil {
+			return nil, err
+		}
+		resp, err := t.next.RoundTrip(req)
+		if err == nil {
+			t.syncCSRF(resp)
+		}
+		return resp, err
+	}
+
+	req = t.withCSRF(req)
+
+	// buffer the body so it can be sent again
+	if req.Body != nil && req.GetBody == nil {
+		req = req.Clone(req.Context())
+		if _, err := readBody(req); err != nil {
+			return nil, err
+		}
+	}
+
+	relogged := false
+	for attempt := 0; ; attempt++ {
+		attemptReq, err := rewindRequest(req)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if err == nil {
+			t.syncCSRF(resp)
+		}
+
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && !relogged && t.canLogin() {
+			drainBody(resp)
+			relogged = true
+
+			log.Printf("[DEBUG] Unifi session expired, logging in again before retrying %s %s", req.Method, req.URL.Path)
+			if err := t.login(req.Context()); err != nil {
+				return nil, fmt.Errorf("unable to log in again after session expired: %w", err)
+			}
+
+			req = t.withCSRF(t.refreshSession(req))
+			// does not count towards retries
+			attempt--
+			continue
+		}
+
+		if attempt >= t.maxRetries || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt)
+		if err != nil {
+			log.Printf("[DEBUG] Unifi request %s %s failed, retrying in %s: %s", req.Method, req.URL.Path, wait, err)
+		} else {
+			log.Printf("[DEBUG] Unifi request %s %s returned %s, retrying in %s", req.Method, req.URL.Path, resp.Status, wait)
+			drainBody(resp)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	wait := t.waitMin << uint(attempt)
+	if wait <= 0 || wait > t.waitMax {
+		wait = t.waitMax
+	}
+	return wait
+}
+
+func (t *retryTransport) recordLogin(req *http.Request) error {
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+
+	t.loginMu.Lock()
+	defer t.loginMu.Unlock()
+	t.loginURL = req.URL.String()
+	t.loginBody = body
+	return nil
+}
+
+func (t *retryTransport) canLogin() bool {
+	t.loginMu.Lock()
+	defer t.loginMu.Unlock()
+	return t.loginURL != ""
+}
+
+// login replays the recorded login request and stores the new session cookies
+// and the CSRF token issued by UniFi OS consoles, if any.
+func (t *retryTransport) login(ctx context.Context) error {
+	t.loginMu.Lock()
+	defer t.loginMu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.loginURL, bytes.NewReader(t.loginBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "terraform-provider-unifi/0.1")
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	defer drainBody(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("login returned %s", resp.Status)
+	}
+
+	if t.jar != nil {
+		t.jar.SetCookies(req.URL, resp.Cookies())
+	}
+	t.syncCSRF(resp)
+
+	return nil
+}
+
+// syncCSRF stores the CSRF token sent by the controller. Responses without a
+// token get the stored one, so the SDK, which keeps the token of the last
+// response, picks up the token of a session the transport logged in again.
+func (t *retryTransport) syncCSRF(resp *http.Response) {
+	t.csrfMu.Lock()
+	defer t.csrfMu.Unlock()
+
+	if csrf := resp.Header.Get("X-CSRF-Token"); csrf != "" {
+		t.csrf = csrf
+		return
+	}
+	if t.csrf != "" && resp.Header != nil {
+		resp.Header.Set("X-CSRF-Token", t.csrf)
+	}
+}
+
+// withCSRF returns the request with the latest CSRF token, requests made
+// outside of the SDK can still carry the token of an expired session.
+func (t *retryTransport) withCSRF(req *http.Request) *http.Request {
+	t.csrfMu.Lock()
+	defer t.csrfMu.Unlock()
+
+	if t.csrf == "" || req.Header.Get("X-CSRF-Token") == t.csrf {
+		return req
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("X-CSRF-Token", t.csrf)
+	return req
+}
+
+// refreshSession returns a copy of the request with the cookies of the new
+// session.
+func (t *retryTransport) refreshSession(req *http.Request) *http.Request {
+	req = req.Clone(req.Context())
+	if t.jar != nil {
+		req.Header.Del("Cookie")
+		for _, c := range t.jar.Cookies(req.URL) {
+			req.AddCookie(c)
+		}
+	}
+	return req
+}
+
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			// nothing was sent, always safe to retry
+			return true
+		}
+		if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return isIdempotent(req.Method)
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return isIdempotent(req.Method)
+		}
+		return false
+	}
+
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		// the controller did not handle the request
+		return true
+	}
+	// after a 502 or 504 the proxy gave up waiting, but the controller may
+	// still have applied the request
+	return resp.StatusCode >= 500 && isIdempotent(req.Method)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.GetBody == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Body = body
+	return req, nil
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return ioutil.ReadAll(body)
+	}
+	b, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	}
+	return b, nil
+}
+
+func drainBody(resp *http.Response) {
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+}
@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func newTestRetryClient(maxRetries int) *http.Client {
+	jar, _ := cookiejar.New(nil)
+	return &http.Client{
+		Jar: jar,
+		Transport: &retryTransport{
+			next:       http.DefaultTransport,
+			jar:        jar,
+			maxRetries: maxRetries,
+			waitMin:    time.Millisecond,
+			waitMax:    5 * time.Millisecond,
+		},
+	}
+}
+
+func TestRetryTransport_serverErrors(t *testing.T) {
+	for _, c := range []struct {
+		name          string
+		method        string
+		status        int
+		maxRetries    int
+		expectedCalls int32
+	}{
+		{"get retried until success", "GET", http.StatusInternalServerError, 3, 3},
+		{"get gives up", "GET", http.StatusInternalServerError, 1, 2},
+		{"post not retried on 500", "POST", http.StatusInternalServerError, 3, 1},
+		{"post retried on 503", "POST", http.StatusServiceUnavailable, 3, 3},
+		{"post not retried on 502", "POST", http.StatusBadGateway, 3, 1},
+		{"post not retried on 504", "POST", http.StatusGatewayTimeout, 3, 1},
+		{"put retried on 504", "PUT", http.StatusGatewayTimeout, 3, 3},
+		{"retries disabled", "GET", http.StatusServiceUnavailable, 0, 1},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var calls int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// fail the first two calls
+				if atomic.AddInt32(&calls, 1) <= 2 {
+					w.WriteHeader(c.status)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer srv.Close()
+
+			req, err := http.NewRequest(c.method, srv.URL+"/api/s/default/rest/user", strings.NewReader(`{"name":"x"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := newTestRetryClient(c.maxRetries).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if actual := atomic.LoadInt32(&calls); actual != c.expectedCalls {
+				t.Fatalf("expected %d calls, got %d", c.expectedCalls, actual)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_relogin(t *testing.T) {
+	var logins int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/login":
+			n := atomic.AddInt32(&logins, 1)
+			http.SetCookie(w, &http.Cookie{Name: "unifises", Value: strconv.Itoa(int(n))})
+			w.Header().Set("X-CSRF-Token", "csrf-"+strconv.Itoa(int(n)))
+			w.WriteHeader(http.StatusOK)
+		default:
+			// only the second session is valid
+			if c, err := r.Cookie("unifises"); err != nil || c.Value != "2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if actual := r.Header.Get("X-CSRF-Token"); actual != "csrf-2" {
+				t.Errorf("expected CSRF token %q, got %q", "csrf-2", actual)
+			}
+			if r.ContentLength <= 0 {
+				t.Errorf("expected request body to be replayed")
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	hc := newTestRetryClient(0)
+
+	resp, err := hc.Post(srv.URL+"/api/login", "application/json", strings.NewReader(`{"username":"u","password":"p"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	csrf := resp.Header.Get("X-CSRF-Token")
+
+	// the client sends the token of the first session, like the SDK does
+	// until it sees a new one
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest("POST", srv.URL+"/api/s/default/rest/user", strings.NewReader(`{"name":"x"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-CSRF-Token", csrf)
+
+		resp, err = hc.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", resp.StatusCode)
+		}
+		if actual := resp.Header.Get("X-CSRF-Token"); actual != "csrf-2" {
+			t.Fatalf("expected response CSRF token %q, got %q", "csrf-2", actual)
+		}
+	}
+
+	if actual := atomic.LoadInt32(&logins); actual != 2 {
+		t.Fatalf("expected 2 logins, got %d", actual)
+	}
+}
+
+func TestProvider_retryWaits(t *testing.T) {
+	for _, c := range []struct {
+		name          string
+		min           string
+		max           string
+		expectedError string
+	}{
+		{"valid", "1s", "30s", ""},
+		{"equal", "1s", "1s", ""},
+		{"negative", "-1s", "30s", "retry_wait_min must be a positive duration"},
+		{"zero", "1s", "0s", "retry_wait_max must be a positive duration"},
+		{"min greater than max", "1m", "30s", "retry_wait_min (1m0s) must not be greater than retry_wait_max (30s)"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			p := New("test")()
+			cfg := terraform.NewResourceConfigRaw(map[string]interface{}{
+				"username":       "u",
+				"password":       "p",
+				"api_url":        "https://localhost",
+				"retry_wait_min": c.min,
+				"retry_wait_max": c.max,
+			})
+
+			diags := p.Validate(cfg)
+			if !diags.HasError() {
+				diags = p.Configure(context.Background(), cfg)
+			}
+
+			if c.expectedError == "" {
+				if diags.HasError() {
+					t.Fatalf("unexpected error: %s", diagsString(diags))
+				}
+				return
+			}
+			if !strings.Contains(diagsString(diags), c.expectedError) {
+				t.Fatalf("expected error containing %q, got %q", c.expectedError, diagsString(diags))
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package provider
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -25,3 +27,20 @@ func timeFromUnifi(t string) string {
 	i := len(t) - 2
 	return strings.TrimPrefix(t[0:i], "0") + ":" + t[i:]
 }
+
+func validateDuration(raw interface{}, key string) ([]string, []error) {
+	v, ok := raw.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected string, got %T", raw)}
+	}
+
+	dur, err := time.ParseDuration(v)
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s: %w", key, err)}
+	}
+	if dur <= 0 {
+		return nil, []error{fmt.Errorf("%s must be a positive duration, got %s", key, v)}
+	}
+
+	return nil, nil
+}
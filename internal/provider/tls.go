@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// fingerprintMismatchError is returned when the certificate presented by the
+// controller does not match the pinned fingerprint.
+type fingerprintMismatchError struct {
+	expected []byte
+	actual   []byte
+}
+
+func (e *fingerprintMismatchError) Error() string {
+	return fmt.Sprintf("certificate fingerprint %s does not match the pinned fingerprint %s",
+		formatFingerprint(e.actual), formatFingerprint(e.expected))
+}
+
+func formatFingerprint(fp []byte) string {
+	parts := make([]string, 0, len(fp))
+	for _, b := range fp {
+		parts = append(parts, fmt.Sprintf("%02X", b))
+	}
+	return strings.Join(parts, ":")
+}
+
+// parseFingerprint parses a hex encoded SHA-256 fingerprint, optionally
+// separated by colons as shown by browsers and `openssl x509 -fingerprint`.
+func parseFingerprint(s string) ([]byte, error) {
+	clean := strings.NewReplacer(":", "", " ", "").Replace(strings.TrimSpace(s))
+	fp, err := hex.DecodeString(clean)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse fingerprint: %w", err)
+	}
+	if len(fp) != sha256.Size {
+		return nil, fmt.Errorf("expected a %d byte SHA-256 fingerprint, got %d bytes", sha256.Size, len(fp))
+	}
+	return fp, nil
+}
+
+func validateFingerprint(raw interface{}, key string) ([]string, []error) {
+	v, ok := raw.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected string, got %T", raw)}
+	}
+	if v == "" {
+		return nil, nil
+	}
+	if _, err := parseFingerprint(v); err != nil {
+		return nil, []error{fmt.Errorf("%s: %w", key, err)}
+	}
+	return nil, nil
+}
+
+// loadCertPool returns the system roots with the additional certificates from
+// the given file and PEM data appended.
+func loadCertPool(caCertFile, caCertPEM string) (*x509.CertPool, error) {
+	if caCertFile == "" && caCertPEM == "" {
+		return nil, nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	if caCertFile != "" {
+		pem, err := ioutil.ReadFile(caCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read ca_cert_file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in ca_cert_file %q", caCertFile)
+		}
+	}
+
+	if caCertPEM != "" {
+		if !pool.AppendCertsFromPEM([]byte(caCertPEM)) {
+			return nil, fmt.Errorf("no certificates found in ca_cert_pem")
+		}
+	}
+
+	return pool, nil
+}
+
+func newTLSConfig(config httpClientConfig) *tls.Config {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.insecure,
+		RootCAs:            config.rootCAs,
+	}
+
+	if len(config.fingerprint) > 0 {
+		// the pinned certificate replaces chain and hostname verification,
+		// so self-signed certificates can be used, it is checked even if
+		// insecure connections are allowed
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return fmt.Errorf("no certificate presented by the controller")
+			}
+			actual := sha256.Sum256(rawCerts[0])
+			if !bytes.Equal(actual[:], config.fingerprint) {
+				return &fingerprintMismatchError{
+					expected: config.fingerprint,
+					actual:   actual[:],
+				}
+			}
+			return nil
+		}
+	}
+
+	return tlsConfig
+}
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFingerprint(t *testing.T) {
+	for _, c := range []struct {
+		input       string
+		expectedErr bool
+	}{
+		{"", true},
+		{"not hex", true},
+		{"AB:CD", true},
+		{"2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae", false},
+		{"2C:26:B4:6B:68:FF:C6:8F:F9:9B:45:3C:1D:30:41:34:13:42:2D:70:64:83:BF:A0:F9:8A:5E:88:62:66:E7:AE", false},
+	} {
+		t.Run(c.input, func(t *testing.T) {
+			fp, err := parseFingerprint(c.input)
+			if c.expectedErr {
+				if err == nil {
+					t.Fatalf("expected error, got %x", fp)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(fp) != sha256.Size {
+				t.Fatalf("expected %d bytes, got %d", sha256.Size, len(fp))
+			}
+		})
+	}
+}
+
+func TestLazyClientVersion_tls(t *testing.T) {
+	srv := newStandInController(t, true, "pass", "6.0.43")
+	defer srv.Close()
+
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+	certFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := ioutil.WriteFile(certFile, []byte(certPEM), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fingerprint := sha256.Sum256(srv.Certificate().Raw)
+
+	for _, c := range []struct {
+		name        string
+		caCertFile  string
+		caCertPEM   string
+		fingerprint string
+		insecure    bool
+
+		expectedErr bool
+	}{
+		{name: "ca_cert_file", caCertFile: certFile},
+		{name: "ca_cert_pem", caCertPEM: certPEM},
+		{name: "fingerprint", fingerprint: formatFingerprint(fingerprint[:])},
+		{name: "fingerprint mismatch", fingerprint: fmt.Sprintf("%x", sha256.Sum256([]byte("other"))), expectedErr: true},
+		{name: "insecure", insecure: true},
+		{name: "insecure fingerprint", fingerprint: formatFingerprint(fingerprint[:]), insecure: true},
+		{name: "insecure fingerprint mismatch", fingerprint: fmt.Sprintf("%x", sha256.Sum256([]byte("other"))), insecure: true, expectedErr: true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			rootCAs, err := loadCertPool(c.caCertFile, c.caCertPEM)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var fp []byte
+			if c.fingerprint != "" {
+				fp, err = parseFingerprint(c.fingerprint)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			lc := &lazyClient{
+				baseURL: srv.URL,
+				user:    "user",
+				pass:    "pass",
+				httpConfig: httpClientConfig{
+					insecure:    c.insecure,
+					rootCAs:     rootCAs,
+					fingerprint: fp,
+				},
+			}
+
+			_, err = lc.Version(context.Background())
+			if !c.expectedErr {
+				if err != nil {
+					t.Fatalf("expected no error, got %s", err)
+				}
+				return
+			}
+
+			var ce *controllerError
+			if !errors.As(err, &ce) || ce.kind != controllerErrorTLS {
+				t.Fatalf("expected TLS controller error, got %#v", err)
+			}
+			var fpErr *fingerprintMismatchError
+			if !errors.As(err, &fpErr) {
+				t.Fatalf("expected fingerprint mismatch, got %s", err)
+			}
+		})
+	}
+}
+
+func TestLoadCertPool_invalid(t *testing.T) {
+	if _, err := loadCertPool("", "not a certificate"); err == nil {
+		t.Fatal("expected error for invalid PEM")
+	}
+	if _, err := loadCertPool(filepath.Join(t.TempDir(), "missing.pem"), ""); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
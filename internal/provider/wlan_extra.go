@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/paultyng/go-unifi/unifi"
+)
+
+// wlanExtra holds the WLAN settings that unifi.WLAN does not support yet. They
+// are read and written with separate requests against the same wlanconf
+// object, the controller merges them with the fields sent by the SDK. Fields
+// are omitted when nil, so settings unknown to the controller version are not
+// sent.
+type wlanExtra struct {
+	WPA3Support    *bool `json:"wpa3_support,omitempty"`
+	WPA3Transition *bool `json:"wpa3_transition,omitempty"`
+
+	MinRSSIEnabled *bool `json:"minrssi_enabled,omitempty"`
+	MinRSSI        *int  `json:"minrssi,omitempty"`
+
+	PrivatePresharedKeysEnabled *bool                      `json:"private_preshared_keys_enabled,omitempty"`
+	PrivatePresharedKeys        *[]wlanPrivatePresharedKey `json:"private_preshared_keys,omitempty"`
+}
+
+type wlanPrivatePresharedKey struct {
+	Password  string `json:"password"`
+	NetworkID string `json:"networkconf_id"`
+}
+
+func (c *lazyClient) GetWLANExtra(ctx context.Context, site, id string) (*wlanExtra, error) {
+	if err := c.init(ctx); err != nil {
+		return nil, err
+	}
+
+	var respBody struct {
+		Data []wlanExtra `json:"data"`
+	}
+	err := c.doV1(ctx, "GET", fmt.Sprintf("s/%s/rest/wlanconf/%s", site, id), nil, &respBody)
+	if err != nil {
+		return nil, err
+	}
+	if len(respBody.Data) != 1 {
+		return nil, &unifi.NotFoundError{}
+	}
+	return &respBody.Data[0], nil
+}
+
+func (c *lazyClient) UpdateWLANExtra(ctx context.Context, site, id string, d *wlanExtra) (*wlanExtra, error) {
+	if err := c.init(ctx); err != nil {
+		return nil, err
+	}
+
+	var respBody struct {
+		Data []wlanExtra `json:"data"`
+	}
+	err := c.doV1(ctx, "PUT", fmt.Sprintf("s/%s/rest/wlanconf/%s", site, id), d, &respBody)
+	if err != nil {
+		return nil, err
+	}
+	if len(respBody.Data) != 1 {
+		return nil, &unifi.NotFoundError{}
+	}
+	return &respBody.Data[0], nil
+}
+
+// wlanAttributeFields are the JSON fields of the version specific attributes
+// of unifi_wlan. unifi.WLAN always sends them, so CreateWLAN and UpdateWLAN
+// leave out the ones the controller version does not support.
+var wlanAttributeFields = map[string][]string{
+	"network_id":    {"networkconf_id"},
+	"ap_group_ids":  {"ap_group_ids"},
+	"wlan_band":     {"wlan_band"},
+	"vlan_id":       {"vlan", "vlan_enabled"},
+	"wlan_group_id": {"wlangroup_id"},
+}
+
+func (c *lazyClient) CreateWLAN(ctx context.Context, site string, d *unifi.WLAN) (*unifi.WLAN, error) {
+	// like the SDK, the controller requires a schedule
+	if d.Schedule == nil {
+		d.Schedule = []string{}
+	}
+	return c.writeWLAN(ctx, "POST", fmt.Sprintf("s/%s/rest/wlanconf", site), d)
+}
+
+func (c *lazyClient) UpdateWLAN(ctx context.Context, site string, d *unifi.WLAN) (*unifi.WLAN, error) {
+	return c.writeWLAN(ctx, "PUT", fmt.Sprintf("s/%s/rest/wlanconf/%s", site, d.ID), d)
+}
+
+func (c *lazyClient) writeWLAN(ctx context.Context, method, relativeURL string, d *unifi.WLAN) (*unifi.WLAN, error) {
+	if err := c.init(ctx); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	var reqBody map[string]interface{}
+	if err := json.Unmarshal(b, &reqBody); err != nil {
+		return nil, err
+	}
+	for attr, fields := range wlanAttributeFields {
+		if supportsAttribute("unifi_wlan", attr, c.version) {
+			continue
+		}
+		for _, f := range fields {
+			delete(reqBody, f)
+		}
+	}
+
+	var respBody struct {
+		Data []unifi.WLAN `json:"data"`
+	}
+	err = c.doV1(ctx, method, relativeURL, reqBody, &respBody)
+	if err != nil {
+		return nil, err
+	}
+	if len(respBody.Data) != 1 {
+		return nil, &unifi.NotFoundError{}
+	}
+	return &respBody.Data[0], nil
+}